@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+var (
+	errTokenRevoked    = errors.New("access token has been revoked")
+	errAPITokenInvalid = errors.New("API token is invalid, expired or revoked")
+)
+
+// scopeError refuses a personal API token that lacks the scope an endpoint
+// requires. An empty scope means the endpoint only accepts a signed-in
+// session.
+type scopeError struct {
+	scope auth.Scope
+}
+
+// rateLimitError refuses a personal API token whose user has used up their
+// plan's API rate limit for now.
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e rateLimitError) Error() string {
+	return "API rate limit exceeded"
+}
+
+func (e scopeError) Error() string {
+	if len(e.scope) == 0 {
+		return "API tokens can't be used here"
+	}
+	return fmt.Sprintf("API token is missing the %s scope", e.scope)
+}
+
+// authenticate returns the user the request's bearer token belongs to. It
+// accepts access tokens, which can do anything their user can, and personal
+// API tokens carrying the given scope. Requests made with API tokens count
+// towards the user's plan's API rate limit.
+func (cfg *apiConfig) authenticate(r *http.Request, scope auth.Scope) (uuid.UUID, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !auth.IsAPIToken(token) {
+		accessToken, err := cfg.authenticateAccessToken(r.Context(), token)
+		return accessToken.UserID, err
+	}
+	apiToken, err := cfg.db.UseAPIToken(r.Context(), auth.HashAPIToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, errAPITokenInvalid
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !auth.HasScope(apiToken.Scopes, scope) {
+		return uuid.Nil, scopeError{scope: scope}
+	}
+	limits, err := cfg.entitlements.ForUser(r.Context(), apiToken.UserID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if limits.APIRateLimit > 0 {
+		if ok, wait := cfg.apiRateLimiter.Allow(apiToken.UserID.String(), limits.APIRateLimit); !ok {
+			return uuid.Nil, rateLimitError{retryAfter: wait}
+		}
+	}
+	return apiToken.UserID, nil
+}
+
+// authenticateSession is authenticate for endpoints that manage the account's
+// credentials, which only a signed-in user may use.
+func (cfg *apiConfig) authenticateSession(r *http.Request) (uuid.UUID, error) {
+	accessToken, err := cfg.sessionAccessToken(r)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return accessToken.UserID, nil
+}
+
+// sessionAccessToken checks that the request carries a current access JWT
+// and returns what it says about its bearer.
+func (cfg *apiConfig) sessionAccessToken(r *http.Request) (auth.AccessToken, error) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return auth.AccessToken{}, err
+	}
+	if auth.IsAPIToken(token) {
+		return auth.AccessToken{}, scopeError{}
+	}
+	return cfg.authenticateAccessToken(r.Context(), token)
+}
+
+// authenticateAccessToken checks an access JWT. Tokens issued before the
+// user's token version was last bumped, by logging out everywhere, changing
+// password or changing role, are rejected.
+func (cfg *apiConfig) authenticateAccessToken(ctx context.Context, token string) (auth.AccessToken, error) {
+	accessToken, err := cfg.keys.ParseJWT(token)
+	if err != nil {
+		return auth.AccessToken{}, err
+	}
+	version, err := cfg.tokenVersion(ctx, accessToken.UserID)
+	if err != nil {
+		return auth.AccessToken{}, err
+	}
+	if accessToken.Version != version {
+		return auth.AccessToken{}, errTokenRevoked
+	}
+	return accessToken, nil
+}
+
+// requireRole lets a request through only if its access token was issued to
+// a user with at least the given role. API tokens never carry a role.
+func (cfg *apiConfig) requireRole(role auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accessToken, err := cfg.sessionAccessToken(r)
+		if err != nil {
+			respondUnauthorized(w, err)
+			return
+		}
+		if !accessToken.Role.AtLeast(role) {
+			respondWithError(w, 403, "Forbidden")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// respondUnauthorized tells the client why its access token was refused, in
+// the body and in an RFC 6750 WWW-Authenticate challenge, so that it can
+// tell an expired token that refreshing will fix from one that is broken.
+func respondUnauthorized(w http.ResponseWriter, err error) {
+	var rateErr rateLimitError
+	if errors.As(err, &rateErr) {
+		setRetryAfter(w, rateErr.retryAfter)
+		respondWithError(w, 429, rateErr.Error())
+		return
+	}
+	var scopeErr scopeError
+	if errors.As(err, &scopeErr) {
+		challenge := `Bearer realm="chirpy", error="insufficient_scope"`
+		if len(scopeErr.scope) != 0 {
+			challenge += fmt.Sprintf(`, scope=%q`, scopeErr.scope)
+		}
+		w.Header().Set("WWW-Authenticate", challenge)
+		respondWithError(w, 403, scopeErr.Error())
+		return
+	}
+
+	var msg string
+	switch {
+	case errors.Is(err, auth.ErrNoBearerToken):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="chirpy"`)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	case errors.Is(err, auth.ErrTokenExpired):
+		msg = "Token has expired"
+	case errors.Is(err, auth.ErrTokenMalformed):
+		msg = "Token is malformed"
+	case errors.Is(err, auth.ErrTokenSignatureInvalid):
+		msg = "Token signature is invalid"
+	case errors.Is(err, auth.ErrTokenNotYetValid):
+		msg = "Token is not valid yet"
+	case errors.Is(err, auth.ErrTokenInvalidClaims):
+		msg = "Token has invalid claims"
+	case errors.Is(err, errTokenRevoked):
+		msg = "Token has been revoked"
+	case errors.Is(err, errAPITokenInvalid):
+		msg = "API token is invalid, expired or revoked"
+	default:
+		msg = "Unauthorized"
+	}
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="chirpy", error="invalid_token", error_description=%q`, msg))
+	respondWithError(w, 401, msg)
+}
+
+func (cfg *apiConfig) tokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	if version, ok := cfg.tokenVersions.Get(userID); ok {
+		return version, nil
+	}
+	version, err := cfg.db.GetUserTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	cfg.tokenVersions.Set(userID, version)
+	return version, nil
+}
+
+// revokeAllTokens signs the user out everywhere and revokes their API
+// tokens. Refresh tokens are revoked first so that none can be used to mint
+// an access token at the new version.
+func (cfg *apiConfig) revokeAllTokens(ctx context.Context, userID uuid.UUID) error {
+	if err := cfg.db.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+	if err := cfg.db.RevokeUserAPITokens(ctx, userID); err != nil {
+		return err
+	}
+	version, err := cfg.db.BumpUserTokenVersion(ctx, userID)
+	if err != nil {
+		return err
+	}
+	cfg.tokenVersions.Set(userID, version)
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+type chirpResponse struct {
+	ID           uuid.UUID      `json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Body         string         `json:"body"`
+	UserID       uuid.UUID      `json:"user_id"`
+	InReplyTo    *uuid.UUID     `json:"in_reply_to,omitempty"`
+	RechirpOf    *uuid.UUID     `json:"rechirp_of,omitempty"`
+	QuoteOf      *uuid.UUID     `json:"quote_of,omitempty"`
+	Original     *chirpResponse `json:"original,omitempty"`
+	Deleted      bool           `json:"deleted,omitempty"`
+	ReplyCount   int64          `json:"reply_count"`
+	RechirpCount int64          `json:"rechirp_count"`
+	QuoteCount   int64          `json:"quote_count"`
+	LikeCount    int64          `json:"like_count"`
+	LikedByMe    *bool          `json:"liked_by_me,omitempty"`
+}
+
+func newChirpResponse(chirp database.Chirp) chirpResponse {
+	resp := chirpResponse{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID,
+		Deleted:   chirp.DeletedAt.Valid,
+	}
+	if chirp.InReplyTo.Valid {
+		resp.InReplyTo = &chirp.InReplyTo.UUID
+	}
+	if chirp.RechirpOf.Valid {
+		resp.RechirpOf = &chirp.RechirpOf.UUID
+	}
+	if chirp.QuoteOf.Valid {
+		resp.QuoteOf = &chirp.QuoteOf.UUID
+	}
+	return resp
+}
+
+// chirpResponses converts chirps for output, embedding the chirp each rechirp
+// or quote refers to. Counts for the whole batch, originals included, are
+// loaded with one query per statistic rather than one per chirp. When viewer
+// is set, each chirp also reports whether that user has liked it.
+func (cfg *apiConfig) chirpResponses(ctx context.Context, viewer uuid.NullUUID, chirps []database.Chirp) ([]chirpResponse, error) {
+	var originalIDs []uuid.UUID
+	for _, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			originalIDs = append(originalIDs, chirp.RechirpOf.UUID)
+		} else if chirp.QuoteOf.Valid {
+			originalIDs = append(originalIDs, chirp.QuoteOf.UUID)
+		}
+	}
+	var originals []database.Chirp
+	if len(originalIDs) != 0 {
+		var err error
+		if originals, err = cfg.db.GetChirpsFromIDs(ctx, originalIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	responses, err := cfg.decorateChirps(ctx, viewer, append(slices.Clip(chirps), originals...))
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]chirpResponse, len(originals))
+	for _, original := range responses[len(chirps):] {
+		byID[original.ID] = original
+	}
+	responses = responses[:len(chirps)]
+	for i, chirp := range chirps {
+		ref := chirp.RechirpOf
+		if !ref.Valid {
+			ref = chirp.QuoteOf
+		}
+		if original, ok := byID[ref.UUID]; ref.Valid && ok {
+			responses[i].Original = &original
+		}
+	}
+	return responses, nil
+}
+
+func (cfg *apiConfig) decorateChirps(ctx context.Context, viewer uuid.NullUUID, chirps []database.Chirp) ([]chirpResponse, error) {
+	responses := make([]chirpResponse, 0, len(chirps))
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		responses = append(responses, newChirpResponse(chirp))
+		ids = append(ids, chirp.ID)
+	}
+	if len(ids) == 0 {
+		return responses, nil
+	}
+
+	replyCounts, err := cfg.db.CountRepliesForChirps(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	replies := make(map[uuid.UUID]int64, len(replyCounts))
+	for _, row := range replyCounts {
+		replies[row.ChirpID] = row.ReplyCount
+	}
+
+	rechirpCounts, err := cfg.db.CountRechirpsForChirps(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	rechirps := make(map[uuid.UUID]database.CountRechirpsForChirpsRow, len(rechirpCounts))
+	for _, row := range rechirpCounts {
+		rechirps[row.ChirpID] = row
+	}
+
+	likeCounts, err := cfg.db.CountLikesForChirps(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	likes := make(map[uuid.UUID]int64, len(likeCounts))
+	for _, row := range likeCounts {
+		likes[row.ChirpID] = row.LikeCount
+	}
+
+	var likedByViewer map[uuid.UUID]bool
+	if viewer.Valid {
+		liked, err := cfg.db.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{UserID: viewer.UUID, ChirpIds: ids})
+		if err != nil {
+			return nil, err
+		}
+		likedByViewer = make(map[uuid.UUID]bool, len(liked))
+		for _, id := range liked {
+			likedByViewer[id] = true
+		}
+	}
+
+	for i := range responses {
+		responses[i].ReplyCount = replies[responses[i].ID]
+		responses[i].RechirpCount = rechirps[responses[i].ID].RechirpCount
+		responses[i].QuoteCount = rechirps[responses[i].ID].QuoteCount
+		responses[i].LikeCount = likes[responses[i].ID]
+		if viewer.Valid {
+			liked := likedByViewer[responses[i].ID]
+			responses[i].LikedByMe = &liked
+		}
+	}
+	return responses, nil
+}
+
+// viewerFromRequest identifies the caller of an endpoint that is public but
+// personalizes its output for signed-in users. Missing or invalid tokens are
+// treated as anonymous.
+func (cfg *apiConfig) viewerFromRequest(r *http.Request) uuid.NullUUID {
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsRead)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: userID, Valid: true}
+}
+
+// pruneTombstones removes the deleted chirps that chirp replied to, rechirped
+// or quoted once nothing else refers to them, working up through the thread.
+func (cfg *apiConfig) pruneTombstones(ctx context.Context, chirp database.Chirp) error {
+	for _, ref := range []uuid.NullUUID{chirp.InReplyTo, chirp.RechirpOf, chirp.QuoteOf} {
+		if !ref.Valid {
+			continue
+		}
+		tombstone, err := cfg.db.GetChirpFromID(ctx, ref.UUID)
+		if err != nil {
+			return err
+		}
+		if !tombstone.DeletedAt.Valid {
+			continue
+		}
+		deleted, err := cfg.db.DeleteUnreferencedTombstone(ctx, tombstone.ID)
+		if err != nil {
+			return err
+		}
+		if deleted != 0 {
+			if err := cfg.pruneTombstones(ctx, tombstone); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
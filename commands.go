@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/mailer"
+)
+
+// runCommand runs one of the administrative subcommands instead of starting
+// the server.
+func runCommand(dbURL string, args []string) error {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	queries := database.New(db)
+
+	switch args[0] {
+	case "create-admin":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: chirpy create-admin <email>")
+		}
+		return createAdmin(context.Background(), queries, args[1])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// createAdmin makes the user with the given email an admin, creating the
+// account first if there isn't one. The new account's password comes from
+// ADMIN_PASSWORD, or the first line of standard input.
+func createAdmin(ctx context.Context, db *database.Queries, address string) error {
+	email, err := mailer.ValidateAddress(address)
+	if err != nil {
+		return err
+	}
+
+	user, err := db.GetUserFromEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		password, err := readAdminPassword()
+		if err != nil {
+			return err
+		}
+		hashedPass, err := auth.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		created, err := db.CreateUser(ctx, database.CreateUserParams{Email: email, HashedPassword: hashedPass})
+		if err != nil {
+			return err
+		}
+		user.ID = created.ID
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := db.SetUserRole(ctx, database.SetUserRoleParams{ID: user.ID, Role: string(auth.RoleAdmin)}); err != nil {
+		return err
+	}
+	fmt.Printf("%s is now an admin\n", email)
+	return nil
+}
+
+func readAdminPassword() (string, error) {
+	if password := os.Getenv("ADMIN_PASSWORD"); len(password) != 0 {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "Password for the new admin: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) == 0 {
+		return "", fmt.Errorf("password must not be empty")
+	}
+	return password, nil
+}
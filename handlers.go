@@ -3,23 +3,55 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
-	"sort"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/chirplen"
 	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/entitlements"
+	"github.com/louiehdev/chirpy/internal/mailer"
+	"github.com/louiehdev/chirpy/internal/moderation"
+	"github.com/louiehdev/chirpy/internal/polka"
+	"github.com/louiehdev/chirpy/internal/ratelimit"
 )
 
+const refreshTokenDuration = 60 * 24 * time.Hour
+
 type apiConfig struct {
 	fileserverHits atomic.Int32
 	db             *database.Queries
+	conn           *sql.DB
 	platform       string
-	secret         string
-	polkaKey       string
+	keys           *auth.KeyStore
+	polka          *polka.Verifier
+
+	entitlements   *entitlements.Resolver
+	apiRateLimiter *ratelimit.Limiter
+
+	moderator           *moderation.Filter
+	baseModerationTerms []moderation.Term
+
+	tokenVersions *auth.VersionCache
+
+	secret                     string
+	mailer                     mailer.Mailer
+	verifyEmailURL             string
+	requireVerifiedEmail       bool
+	verificationResendInterval time.Duration
+	passwordResetURL           string
+	passwordResetInterval      time.Duration
+
+	accountLockout auth.LockoutPolicy
+	ipLockout      auth.LockoutPolicy
+
+	subscriptionPeriod      time.Duration
+	subscriptionGracePeriod time.Duration
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
@@ -61,6 +93,11 @@ func (cfg *apiConfig) healthHandler(w http.ResponseWriter, req *http.Request) {
 	w.Write([]byte("OK"))
 }
 
+func (cfg *apiConfig) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, 200, cfg.keys.JWKS())
+}
+
 func (cfg *apiConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
 	var params struct {
 		Email    string `json:"email"`
@@ -73,43 +110,86 @@ func (cfg *apiConfig) loginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	attempt, ok := cfg.beginLoginAttempt(w, r, params.Email)
+	if !ok {
+		return
+	}
+
 	user, err := cfg.db.GetUserFromEmail(r.Context(), params.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		auth.CheckDummyPasswordHash(params.Password)
+		cfg.respondLoginFailed(w, r, attempt, uuid.NullUUID{}, "incorrect email or password")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	match, err := auth.CheckPasswordHash(params.Password, user.HashedPassword)
 	if err != nil {
-		respondWithError(w, 401, "incorrect email or password")
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !match {
+		cfg.respondLoginFailed(w, r, attempt, uuid.NullUUID{UUID: user.ID, Valid: true}, "incorrect email or password")
 		return
 	}
-	if match, _ := auth.CheckPasswordHash(params.Password, user.HashedPassword); !match {
-		respondWithError(w, 401, "incorrect email or password")
+	// With two-factor authentication on, this attempt stays counted until
+	// the second factor is passed too.
+	if user.TotpEnabledAt.Valid {
+		cfg.startMFAChallenge(w, r, user)
 		return
 	}
+	cfg.loginSucceeded(r, params.Email)
+	cfg.respondWithSession(w, r, user)
+}
+
+// userAccessToken is what an access token issued to user now says about them.
+func userAccessToken(user database.User) auth.AccessToken {
+	return auth.AccessToken{UserID: user.ID, Version: user.TokenVersion, Role: auth.Role(user.Role)}
+}
 
-	accessToken, err := auth.MakeJWT(user.ID, cfg.secret, time.Hour)
+// respondWithSession signs the user in, starting a new session, and responds
+// with the user and the session's access and refresh tokens.
+func (cfg *apiConfig) respondWithSession(w http.ResponseWriter, r *http.Request, user database.User) {
+	accessToken, err := cfg.keys.MakeJWT(userAccessToken(user), time.Hour)
 	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	refreshToken, err := cfg.db.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{Token: auth.MakeRefreshToken(), ExpiresAt: time.Now().Add(1440 * time.Hour), UserID: user.ID})
+	refreshToken, err := cfg.db.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
+		Token:     auth.MakeRefreshToken(),
+		ExpiresAt: time.Now().Add(refreshTokenDuration),
+		UserID:    user.ID,
+		FamilyID:  uuid.New(),
+		UserAgent: r.UserAgent(),
+		IpAddress: clientIP(r),
+	})
 	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
 
 	userData := struct {
-		Id           uuid.UUID `json:"id"`
-		CreatedAt    time.Time `json:"created_at"`
-		UpdatedAt    time.Time `json:"updated_at"`
-		Email        string    `json:"email"`
-		IsChirpyRed  bool      `json:"is_chirpy_red"`
-		Token        string    `json:"token"`
-		RefreshToken string    `json:"refresh_token"`
+		Id            uuid.UUID `json:"id"`
+		CreatedAt     time.Time `json:"created_at"`
+		UpdatedAt     time.Time `json:"updated_at"`
+		Email         string    `json:"email"`
+		IsChirpyRed   bool      `json:"is_chirpy_red"`
+		EmailVerified bool      `json:"email_verified"`
+		Role          string    `json:"role"`
+		Token         string    `json:"token"`
+		RefreshToken  string    `json:"refresh_token"`
 	}{
-		Id:           user.ID,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
-		Email:        user.Email,
-		IsChirpyRed:  user.IsChirpyRed,
-		Token:        accessToken,
-		RefreshToken: refreshToken.Token}
+		Id:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		IsChirpyRed:   user.IsChirpyRed,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		Role:          user.Role,
+		Token:         accessToken,
+		RefreshToken:  refreshToken.Token}
 
 	respondWithJSON(w, 200, userData)
 }
@@ -120,51 +200,92 @@ func (cfg *apiConfig) refreshHandler(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, 401, "Unauthorized")
 		return
 	}
-	refreshToken, err := cfg.db.GetRefreshToken(r.Context(), token)
-	if err != nil || time.Now().After(refreshToken.ExpiresAt) {
+	refreshToken, err := cfg.db.RotateRefreshToken(r.Context(), database.RotateRefreshTokenParams{NewToken: auth.MakeRefreshToken(), Token: token, ExpiresAt: time.Now().Add(refreshTokenDuration)})
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := cfg.detectRefreshTokenReuse(r, token); err != nil {
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
 		respondWithError(w, 401, "Unauthorized")
 		return
 	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
 
-	newToken, err := auth.MakeJWT(refreshToken.UserID, cfg.secret, time.Hour)
+	user, err := cfg.db.GetUserFromID(r.Context(), refreshToken.UserID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	newToken, err := cfg.keys.MakeJWT(userAccessToken(user), time.Hour)
 	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
 	tokenData := struct {
-		Token string `json:"token"`
-	}{Token: newToken}
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}{Token: newToken, RefreshToken: refreshToken.Token}
 	respondWithJSON(w, 200, tokenData)
 }
 
+// detectRefreshTokenReuse revokes the whole family of a refresh token that
+// has already been rotated. Only the client holding the latest token should
+// be refreshing, so an old one coming back means it has leaked.
+func (cfg *apiConfig) detectRefreshTokenReuse(r *http.Request, token string) error {
+	refreshToken, err := cfg.db.GetRefreshToken(r.Context(), token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !refreshToken.ReplacedBy.Valid {
+		return nil
+	}
+
+	if err := cfg.db.RevokeRefreshTokenFamily(r.Context(), refreshToken.FamilyID); err != nil {
+		return err
+	}
+	log.Printf("Refresh token reuse for user %s, revoked token family %s", refreshToken.UserID, refreshToken.FamilyID)
+	return cfg.recordSecurityEvent(r, refreshToken.UserID, "refresh_token_reuse")
+}
+
+// recordSecurityEvent notes something that happened to the user's account,
+// and where the request came from, for later investigation.
+func (cfg *apiConfig) recordSecurityEvent(r *http.Request, userID uuid.UUID, eventType string) error {
+	return cfg.db.CreateSecurityEvent(r.Context(), database.CreateSecurityEventParams{
+		UserID:    userID,
+		EventType: eventType,
+		IpAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+}
+
 func (cfg *apiConfig) revokeHandler(w http.ResponseWriter, r *http.Request) {
 	token, err := auth.GetBearerToken(r.Header)
 	if err != nil {
 		respondWithError(w, 401, "Unauthorized")
 		return
 	}
-	refreshToken, err := cfg.db.GetRefreshToken(r.Context(), token)
+	revoked, err := cfg.db.RevokeToken(r.Context(), token)
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
+		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	if err := cfg.db.RevokeToken(r.Context(), database.RevokeTokenParams{Token: refreshToken.Token, RevokedAt: sql.NullTime{Time: time.Now()}, UpdatedAt: time.Now()}); err != nil {
-		respondWithError(w, 500, "Something went wrong")
-		fmt.Println(err)
+	if revoked == 0 {
+		respondWithError(w, 401, "Unauthorized")
 		return
 	}
 	respondWithError(w, 204, "Request Successful")
 }
 
 func (cfg *apiConfig) chirpHandler(w http.ResponseWriter, r *http.Request) {
-	token, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
-		return
-	}
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsWrite)
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
+		respondUnauthorized(w, err)
 		return
 	}
 
@@ -174,19 +295,69 @@ func (cfg *apiConfig) chirpHandler(w http.ResponseWriter, r *http.Request) {
 	if err := decoder.Decode(&params); err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
-	} else if len(params.Body) > 140 {
-		respondWithError(w, 400, "Chirp is too long")
+	}
+	params.UserID = userID
+
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if cfg.requireVerifiedEmail && !user.EmailVerifiedAt.Valid {
+		respondWithError(w, 403, "Email address must be verified before posting")
+		return
+	}
+	if params.RechirpOf.Valid {
+		cfg.createRechirp(w, r, params)
+		return
+	}
+	limits, err := cfg.entitlements.ForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if length, limit := chirplen.Count(params.Body), limits.MaxChirpLength; length > limit {
+		respondWithJSON(w, 400, chirpLengthError{Error: "Chirp is too long", Length: length, Limit: limit})
 		return
-	} else if len(params.Body) <= 140 {
-		params.Body = replaceProfane(params.Body)
-		params.UserID = userID
-		newChirp, err := cfg.db.CreateChirp(r.Context(), params)
+	}
+	if params.InReplyTo.Valid {
+		parent, err := cfg.db.GetChirpFromID(r.Context(), params.InReplyTo.UUID)
+		if err != nil || parent.DeletedAt.Valid {
+			respondWithError(w, 400, "Chirp being replied to does not exist")
+			return
+		}
+	}
+	if params.QuoteOf.Valid {
+		original, err := cfg.originalChirp(r.Context(), params.QuoteOf.UUID)
 		if err != nil {
+			respondWithError(w, 400, "Quoted chirp does not exist")
+			return
+		}
+		params.QuoteOf.UUID = original.ID
+	}
+
+	moderated := cfg.moderator.Check(params.Body)
+	if moderated.Rejected {
+		respondWithError(w, 400, "Chirp contains prohibited content")
+		return
+	}
+	params.Body = moderated.Body
+	newChirp, err := cfg.db.CreateChirp(r.Context(), params)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if len(moderated.Flagged) != 0 {
+		if err := cfg.db.CreateModerationFlag(r.Context(), database.CreateModerationFlagParams{ChirpID: newChirp.ID, Terms: moderated.Flagged}); err != nil {
 			respondWithError(w, 500, "Something went wrong")
 			return
 		}
-		respondWithJSON(w, 201, newChirp)
 	}
+	if err := cfg.indexChirpEntities(r.Context(), newChirp); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	cfg.respondWithChirp(w, r, 201, userID, newChirp)
 }
 
 func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -200,30 +371,37 @@ func (cfg *apiConfig) createUserHandler(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
+	email, err := mailer.ValidateAddress(params.Email)
+	if err != nil {
+		respondWithError(w, 400, "Invalid email address")
+		return
+	}
 	hashedPass, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	userParams := database.CreateUserParams{Email: params.Email, HashedPassword: hashedPass}
+	userParams := database.CreateUserParams{Email: email, HashedPassword: hashedPass}
 	newUser, err := cfg.db.CreateUser(r.Context(), userParams)
 	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
+	// The account exists either way; a failure here can be retried with a
+	// resend.
+	if err := cfg.sendVerificationEmail(r.Context(), newUser.ID, newUser.Email); err != nil {
+		log.Printf("Error sending verification email: %s", err)
+	}
 
 	respondWithJSON(w, 201, newUser)
 }
 
+// updateUserHandler changes the account's email and password, so it needs a
+// signed-in session; an API token could otherwise take the account over.
 func (cfg *apiConfig) updateUserHandler(w http.ResponseWriter, r *http.Request) {
-	token, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
-		return
-	}
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	userID, err := cfg.authenticateSession(r)
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
+		respondUnauthorized(w, err)
 		return
 	}
 
@@ -237,108 +415,107 @@ func (cfg *apiConfig) updateUserHandler(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	hashedPass, err := auth.HashPassword(params.Password)
+	email, err := mailer.ValidateAddress(params.Email)
 	if err != nil {
-		respondWithError(w, 500, "Something went wrong")
+		respondWithError(w, 400, "Invalid email address")
 		return
 	}
-	userParams := database.UpdateUserParams{Email: params.Email, HashedPassword: hashedPass, ID: userID}
-	updatedUser, err := cfg.db.UpdateUser(r.Context(), userParams)
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
 	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	respondWithJSON(w, 200, updatedUser)
-}
-
-func (cfg *apiConfig) upgradeUserHandler(w http.ResponseWriter, r *http.Request) {
-	if key, err := auth.GetAPIKey(r.Header); err != nil || key != cfg.polkaKey {
-		respondWithError(w, 401, "Unauthorized")
-		return
-	}
-
-	var params struct {
-		Event string `json:"event"`
-		Data  struct {
-			UserID string `json:"user_id"`
-		} `json:"data"`
-	}
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&params); err != nil {
+	samePassword, _ := auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	hashedPass, err := auth.HashPassword(params.Password)
+	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	if params.Event != "user.upgraded" {
-		respondWithError(w, 204, "Unknown event")
-		return
-	}
-	userID, _ := uuid.Parse(params.Data.UserID)
-	if err := cfg.db.UpgradeUser(r.Context(), userID); err != nil {
-		respondWithError(w, 404, "User not found")
+	userParams := database.UpdateUserParams{Email: email, HashedPassword: hashedPass, ID: userID}
+	updatedUser, err := cfg.db.UpdateUser(r.Context(), userParams)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-
-	respondWithError(w, 204, "")
-}
-
-func (cfg *apiConfig) getChirpsHandler(w http.ResponseWriter, r *http.Request) {
-	var chirps []database.Chirp
-	authorID := r.URL.Query().Get("author_id")
-	sortBy := r.URL.Query().Get("sort")
-	if len(authorID) == 0 {
-		chirpsQuery, err := cfg.db.GetChirps(r.Context())
-		if err != nil {
+	if updatedUser.Email != user.Email {
+		if err := cfg.sendVerificationEmail(r.Context(), updatedUser.ID, updatedUser.Email); err != nil {
 			respondWithError(w, 500, "Something went wrong")
 			return
 		}
-		chirps = chirpsQuery
-	} else {
-		userID, err := uuid.Parse(authorID)
-		if err != nil {
+	}
+	if !samePassword {
+		if err := cfg.revokeAllTokens(r.Context(), userID); err != nil {
 			respondWithError(w, 500, "Something went wrong")
 			return
 		}
-		chirpsQuery, err := cfg.db.GetChirpsFromUser(r.Context(), userID)
+	}
+	respondWithJSON(w, 200, updatedUser)
+}
+
+func (cfg *apiConfig) getChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r.URL.Query(), false)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	var authorID uuid.NullUUID
+	if author := r.URL.Query().Get("author_id"); len(author) != 0 {
+		userID, err := uuid.Parse(author)
 		if err != nil {
-			respondWithError(w, 500, "Something went wrong")
+			respondWithError(w, 400, "Invalid author_id")
 			return
 		}
-		chirps = chirpsQuery
+		authorID = uuid.NullUUID{UUID: userID, Valid: true}
 	}
 
-	if sortBy == "desc" {
-		sort.Slice(chirps, func(a, b int) bool { return chirps[a].CreatedAt.After(chirps[b].CreatedAt) })
+	chirps, next, prev, err := fetchPage(page, chirpCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.Chirp, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListChirpsAfterParams{AuthorID: authorID, CursorCreatedAt: createdAt, CursorID: id, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListChirpsAfter(r.Context(), params)
+		}
+		return cfg.db.ListChirpsBefore(r.Context(), database.ListChirpsBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	responses, err := cfg.chirpResponses(r.Context(), cfg.viewerFromRequest(r), chirps)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
 	}
 
-	respondWithJSON(w, 200, chirps)
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, responses)
 }
 
 func (cfg *apiConfig) getChirpFromIDHandler(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("chirpID")
 	idParam, _ := uuid.Parse(id)
 	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
-	if err != nil {
+	if err != nil || chirp.DeletedAt.Valid {
 		respondWithError(w, 404, "Chirp not found")
 		return
 	}
-	respondWithJSON(w, 200, chirp)
-}
-
-func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request) {
-	token, err := auth.GetBearerToken(r.Header)
+	responses, err := cfg.chirpResponses(r.Context(), cfg.viewerFromRequest(r), []database.Chirp{chirp})
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
+		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	userID, err := auth.ValidateJWT(token, cfg.secret)
+	respondWithJSON(w, 200, responses[0])
+}
+
+func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsWrite)
 	if err != nil {
-		respondWithError(w, 401, "Unauthorized")
+		respondUnauthorized(w, err)
 		return
 	}
 	id := r.PathValue("chirpID")
 	idParam, _ := uuid.Parse(id)
 	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
-	if err != nil {
+	if err != nil || chirp.DeletedAt.Valid {
 		respondWithError(w, 404, "Chirp not found")
 		return
 	}
@@ -346,7 +523,23 @@ func (cfg *apiConfig) deleteChirpHandler(w http.ResponseWriter, r *http.Request)
 		respondWithError(w, 403, "Unauthorized")
 		return
 	}
-	if err := cfg.db.DeleteChirp(r.Context(), chirp.ID); err != nil {
+
+	// Chirps that others still reply to, rechirp or quote are blanked rather
+	// than removed so threads and reposts degrade to a tombstone.
+	hasDependents, err := cfg.db.ChirpHasDependents(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if hasDependents {
+		if err = cfg.db.TombstoneChirp(r.Context(), chirp.ID); err == nil {
+			chirp.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			err = cfg.indexChirpEntities(r.Context(), chirp)
+		}
+	} else if err = cfg.db.DeleteChirp(r.Context(), chirp.ID); err == nil {
+		err = cfg.pruneTombstones(r.Context(), chirp)
+	}
+	if err != nil {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+type adminUserResponse struct {
+	ID            uuid.UUID `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Email         string    `json:"email"`
+	IsChirpyRed   bool      `json:"is_chirpy_red"`
+	EmailVerified bool      `json:"email_verified"`
+	TOTPEnabled   bool      `json:"totp_enabled"`
+	Role          string    `json:"role"`
+}
+
+func newAdminUserResponse(user database.User) adminUserResponse {
+	return adminUserResponse{
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		IsChirpyRed:   user.IsChirpyRed,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		TOTPEnabled:   user.TotpEnabledAt.Valid,
+		Role:          user.Role,
+	}
+}
+
+func (cfg *apiConfig) adminListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, err := parsePageRequest(query, false)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	var role sql.NullString
+	if name := query.Get("role"); len(name) != 0 {
+		if _, err := auth.ParseRole(name); err != nil {
+			respondWithError(w, 400, "role must be user, moderator or admin")
+			return
+		}
+		role = sql.NullString{String: name, Valid: true}
+	}
+
+	userCursor := func(u database.User) pageCursor { return pageCursor{CreatedAt: u.CreatedAt, ID: u.ID} }
+	users, next, prev, err := fetchPage(page, userCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.User, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListUsersAfterParams{CursorCreatedAt: createdAt, CursorID: id, Role: role, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListUsersAfter(r.Context(), params)
+		}
+		return cfg.db.ListUsersBefore(r.Context(), database.ListUsersBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	responses := make([]adminUserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, newAdminUserResponse(user))
+	}
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, responses)
+}
+
+func (cfg *apiConfig) adminGetUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := cfg.userFromPath(r)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	respondWithJSON(w, 200, newAdminUserResponse(user))
+}
+
+func (cfg *apiConfig) adminSetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	var params struct {
+		Role string `json:"role"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	role, err := auth.ParseRole(params.Role)
+	if err != nil {
+		respondWithError(w, 400, "role must be user, moderator or admin")
+		return
+	}
+	// Admins can't demote themselves, so there is always at least one.
+	if userID == adminID {
+		respondWithError(w, 400, "You can't change your own role")
+		return
+	}
+
+	// Changing the role bumps the token version, so that access tokens
+	// carrying the old role stop working straight away.
+	version, err := cfg.db.SetUserRole(r.Context(), database.SetUserRoleParams{ID: userID, Role: string(role)})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	cfg.tokenVersions.Set(userID, version)
+	if err := cfg.recordSecurityEvent(r, userID, "role_changed"); err != nil {
+		log.Printf("Error recording security event: %s", err)
+	}
+
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, newAdminUserResponse(user))
+}
+
+func (cfg *apiConfig) adminDeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	if userID == adminID {
+		respondWithError(w, 400, "You can't delete your own account here")
+		return
+	}
+	deleted, err := cfg.db.DeleteUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	cfg.tokenVersions.Delete(userID)
+	w.WriteHeader(204)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/chirplen"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) editChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+
+	var params struct {
+		Body string `json:"body"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	id := r.PathValue("chirpID")
+	idParam, _ := uuid.Parse(id)
+	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
+	if err != nil || chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	if chirp.UserID != userID {
+		respondWithError(w, 403, "Unauthorized")
+		return
+	}
+	if chirp.RechirpOf.Valid {
+		respondWithError(w, 400, "Rechirps cannot be edited")
+		return
+	}
+	limits, err := cfg.entitlements.ForUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if time.Since(chirp.CreatedAt) > time.Duration(limits.EditWindow) {
+		respondWithError(w, 403, "Edit window has expired")
+		return
+	}
+	if length, limit := chirplen.Count(params.Body), limits.MaxChirpLength; length > limit {
+		respondWithJSON(w, 400, chirpLengthError{Error: "Chirp is too long", Length: length, Limit: limit})
+		return
+	}
+
+	moderated := cfg.moderator.Check(params.Body)
+	if moderated.Rejected {
+		respondWithError(w, 400, "Chirp contains prohibited content")
+		return
+	}
+
+	updatedChirp, err := cfg.db.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{ID: chirp.ID, Body: moderated.Body})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if len(moderated.Flagged) != 0 {
+		if err := cfg.db.CreateModerationFlag(r.Context(), database.CreateModerationFlagParams{ChirpID: updatedChirp.ID, Terms: moderated.Flagged}); err != nil {
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+	}
+	if err := cfg.indexChirpEntities(r.Context(), updatedChirp); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	cfg.respondWithChirp(w, r, 200, userID, updatedChirp)
+}
+
+func (cfg *apiConfig) getChirpRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("chirpID")
+	idParam, _ := uuid.Parse(id)
+	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
+	if err != nil || chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	revisions, err := cfg.db.ListChirpRevisions(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if revisions == nil {
+		revisions = []database.ChirpRevision{}
+	}
+	respondWithJSON(w, 200, revisions)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+type followResponse struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func (cfg *apiConfig) followHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeAccountWrite)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	followee, err := cfg.userFromPath(r)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	if followee.ID == userID {
+		respondWithError(w, 400, "You cannot follow yourself")
+		return
+	}
+
+	if err := cfg.db.FollowUser(r.Context(), database.FollowUserParams{FollowerID: userID, FolloweeID: followee.ID}); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) unfollowHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeAccountWrite)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	followee, err := cfg.userFromPath(r)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	if err := cfg.db.UnfollowUser(r.Context(), database.UnfollowUserParams{FollowerID: userID, FolloweeID: followee.ID}); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) getFollowersHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r.URL.Query(), true)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	user, err := cfg.userFromPath(r)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	followerCursor := func(f database.Follow) pageCursor { return pageCursor{CreatedAt: f.CreatedAt, ID: f.FollowerID} }
+	follows, next, prev, err := fetchPage(page, followerCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.Follow, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListFollowersAfterParams{UserID: user.ID, CursorCreatedAt: createdAt, CursorID: id, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListFollowersAfter(r.Context(), params)
+		}
+		return cfg.db.ListFollowersBefore(r.Context(), database.ListFollowersBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	followers := make([]followResponse, 0, len(follows))
+	for _, f := range follows {
+		followers = append(followers, followResponse{UserID: f.FollowerID, FollowedAt: f.CreatedAt})
+	}
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, followers)
+}
+
+func (cfg *apiConfig) getFollowingHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r.URL.Query(), true)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	user, err := cfg.userFromPath(r)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	followeeCursor := func(f database.Follow) pageCursor { return pageCursor{CreatedAt: f.CreatedAt, ID: f.FolloweeID} }
+	follows, next, prev, err := fetchPage(page, followeeCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.Follow, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListFollowingAfterParams{UserID: user.ID, CursorCreatedAt: createdAt, CursorID: id, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListFollowingAfter(r.Context(), params)
+		}
+		return cfg.db.ListFollowingBefore(r.Context(), database.ListFollowingBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	following := make([]followResponse, 0, len(follows))
+	for _, f := range follows {
+		following = append(following, followResponse{UserID: f.FolloweeID, FollowedAt: f.CreatedAt})
+	}
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, following)
+}
+
+func (cfg *apiConfig) timelineHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsRead)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	page, err := parsePageRequest(r.URL.Query(), true)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	chirps, next, prev, err := fetchPage(page, chirpCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.Chirp, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListTimelineAfterParams{UserID: userID, CursorCreatedAt: createdAt, CursorID: id, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListTimelineAfter(r.Context(), params)
+		}
+		return cfg.db.ListTimelineBefore(r.Context(), database.ListTimelineBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	responses, err := cfg.chirpResponses(r.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, responses)
+}
+
+func (cfg *apiConfig) userFromPath(r *http.Request) (database.User, error) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return database.User{}, err
+	}
+	return cfg.db.GetUserFromID(r.Context(), userID)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+// trendingWindows maps each supported window to the half-life used to decay
+// older uses of a tag, so a burst early in the window ranks below a steady
+// stream near its end.
+var trendingWindows = map[string]struct {
+	window   time.Duration
+	halfLife time.Duration
+}{
+	"1h":  {time.Hour, 15 * time.Minute},
+	"24h": {24 * time.Hour, 6 * time.Hour},
+	"7d":  {7 * 24 * time.Hour, 42 * time.Hour},
+}
+
+func (cfg *apiConfig) getHashtagChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r.URL.Query(), true)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	tag := strings.ToLower(strings.TrimPrefix(r.PathValue("tag"), "#"))
+
+	chirps, next, prev, err := fetchPage(page, chirpCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.Chirp, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListHashtagChirpsAfterParams{Tag: tag, CursorCreatedAt: createdAt, CursorID: id, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListHashtagChirpsAfter(r.Context(), params)
+		}
+		return cfg.db.ListHashtagChirpsBefore(r.Context(), database.ListHashtagChirpsBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	responses, err := cfg.chirpResponses(r.Context(), cfg.viewerFromRequest(r), chirps)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, responses)
+}
+
+func (cfg *apiConfig) trendingHandler(w http.ResponseWriter, r *http.Request) {
+	windowName := r.URL.Query().Get("window")
+	if len(windowName) == 0 {
+		windowName = "24h"
+	}
+	window, ok := trendingWindows[windowName]
+	if !ok {
+		respondWithError(w, 400, "window must be 1h, 24h or 7d")
+		return
+	}
+	limit := 10
+	if value := r.URL.Query().Get("limit"); len(value) != 0 {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxPageLimit {
+			respondWithError(w, 400, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	trending, err := cfg.db.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		HalfLifeSeconds: window.halfLife.Seconds(),
+		WindowSeconds:   window.window.Seconds(),
+		RowLimit:        int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if trending == nil {
+		trending = []database.GetTrendingHashtagsRow{}
+	}
+	respondWithJSON(w, 200, trending)
+}
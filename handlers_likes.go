@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+type likeResponse struct {
+	UserID  uuid.UUID `json:"user_id"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+func (cfg *apiConfig) likeChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	id := r.PathValue("chirpID")
+	idParam, _ := uuid.Parse(id)
+	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
+	if err != nil || chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	if err := cfg.db.LikeChirp(r.Context(), database.LikeChirpParams{ChirpID: chirp.ID, UserID: userID}); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) unlikeChirpHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	id := r.PathValue("chirpID")
+	idParam, _ := uuid.Parse(id)
+
+	if err := cfg.db.UnlikeChirp(r.Context(), database.UnlikeChirpParams{ChirpID: idParam, UserID: userID}); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) getChirpLikesHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r.URL.Query(), true)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	id := r.PathValue("chirpID")
+	idParam, _ := uuid.Parse(id)
+	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
+	if err != nil || chirp.DeletedAt.Valid {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	likeCursor := func(l database.ChirpLike) pageCursor { return pageCursor{CreatedAt: l.CreatedAt, ID: l.UserID} }
+	likes, next, prev, err := fetchPage(page, likeCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.ChirpLike, error) {
+		createdAt, id := cursorArgs(cursor)
+		params := database.ListChirpLikesAfterParams{ChirpID: chirp.ID, CursorCreatedAt: createdAt, CursorID: id, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListChirpLikesAfter(r.Context(), params)
+		}
+		return cfg.db.ListChirpLikesBefore(r.Context(), database.ListChirpLikesBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	responses := make([]likeResponse, 0, len(likes))
+	for _, l := range likes {
+		responses = append(responses, likeResponse{UserID: l.UserID, LikedAt: l.CreatedAt})
+	}
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, responses)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+// Failed logins are counted per email address, whether or not an account
+// exists for it, and per client address.
+func accountLoginKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+type loginLimit struct {
+	key     string
+	policy  auth.LockoutPolicy
+	account bool
+}
+
+// loginLimits are the counts a login for email is checked against. The
+// account's comes first, so that concurrent logins always lock them in the
+// same order.
+func (cfg *apiConfig) loginLimits(r *http.Request, email string) []loginLimit {
+	return []loginLimit{
+		{accountLoginKey(email), cfg.accountLockout, true},
+		{ipLoginKey(clientIP(r)), cfg.ipLockout, false},
+	}
+}
+
+// loginAttempt is what a counted login attempt costs if the password turns
+// out to be wrong.
+type loginAttempt struct {
+	wait time.Duration
+	// accountLocked is set when this attempt brought the email to the
+	// lockout threshold.
+	accountLocked bool
+}
+
+// beginLoginAttempt counts a login against the email and the client before
+// the password is checked, and blocks further attempts for as long as this
+// one will cost if it fails. The counts are locked while that happens, so
+// concurrent guesses each see the ones before them and can't all slip in
+// ahead of the block. If either is still blocked it responds with 429 and
+// reports false.
+func (cfg *apiConfig) beginLoginAttempt(w http.ResponseWriter, r *http.Request, email string) (loginAttempt, bool) {
+	limits := cfg.loginLimits(r, email)
+	now := time.Now()
+	keys := make([]string, 0, len(limits))
+	for _, limit := range limits {
+		keys = append(keys, limit.key)
+	}
+
+	tx, err := cfg.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return loginAttempt{}, false
+	}
+	defer tx.Rollback()
+	queries := cfg.db.WithTx(tx)
+	if err := queries.EnsureLoginFailures(r.Context(), database.EnsureLoginFailuresParams{Now: now, Keys: keys}); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return loginAttempt{}, false
+	}
+
+	counts := make([]database.LoginFailure, 0, len(limits))
+	var blockedFor time.Duration
+	for _, limit := range limits {
+		count, err := queries.LockLoginFailure(r.Context(), limit.key)
+		if err != nil {
+			respondWithError(w, 500, "Something went wrong")
+			return loginAttempt{}, false
+		}
+		blockedFor = max(blockedFor, count.BlockedUntil.Sub(now))
+		counts = append(counts, count)
+	}
+	if blockedFor > 0 {
+		setRetryAfter(w, blockedFor)
+		respondWithError(w, 429, "Too many failed login attempts, try again later")
+		return loginAttempt{}, false
+	}
+
+	var attempt loginAttempt
+	for i, limit := range limits {
+		failures := int(counts[i].Failures)
+		if counts[i].LastFailedAt.Before(now.Add(-limit.policy.Window)) {
+			failures = 0
+		}
+		failures++
+		delay := limit.policy.Delay(failures)
+		if err := queries.UpdateLoginFailure(r.Context(), database.UpdateLoginFailureParams{
+			Key:          limit.key,
+			Failures:     int32(failures),
+			LastFailedAt: now,
+			BlockedUntil: now.Add(delay),
+		}); err != nil {
+			respondWithError(w, 500, "Something went wrong")
+			return loginAttempt{}, false
+		}
+		attempt.wait = max(attempt.wait, delay)
+		if limit.account && limit.policy.Locked(failures) && !limit.policy.Locked(failures-1) {
+			attempt.accountLocked = true
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return loginAttempt{}, false
+	}
+	return attempt, true
+}
+
+// respondLoginFailed refuses a login whose password or second factor was
+// wrong, telling the client how long it has to wait before trying again.
+func (cfg *apiConfig) respondLoginFailed(w http.ResponseWriter, r *http.Request, attempt loginAttempt, userID uuid.NullUUID, msg string) {
+	if attempt.accountLocked && userID.Valid {
+		if err := cfg.recordSecurityEvent(r, userID.UUID, "account_locked"); err != nil {
+			log.Printf("Error recording security event: %s", err)
+		}
+	}
+	if attempt.wait > 0 {
+		setRetryAfter(w, attempt.wait)
+	}
+	respondWithError(w, 401, msg)
+}
+
+// loginSucceeded forgets the email's failed logins and takes the attempt
+// back off the client's count. It is called only once the user has passed
+// every factor, so that knowing the password alone doesn't reset the count. Any block the client is under is left to
+// expire, so that signing in to one account doesn't buy more guesses
+// against others.
+func (cfg *apiConfig) loginSucceeded(r *http.Request, email string) {
+	if _, err := cfg.db.ClearLoginFailures(r.Context(), []string{accountLoginKey(email)}); err != nil {
+		log.Printf("Error clearing login failures: %s", err)
+	}
+	if err := cfg.db.RefundLoginAttempt(r.Context(), ipLoginKey(clientIP(r))); err != nil {
+		log.Printf("Error clearing login failures: %s", err)
+	}
+}
+
+func (cfg *apiConfig) unlockLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Email     string `json:"email"`
+		IpAddress string `json:"ip_address"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	var keys []string
+	if len(params.Email) != 0 {
+		keys = append(keys, accountLoginKey(params.Email))
+	}
+	if len(params.IpAddress) != 0 {
+		keys = append(keys, ipLoginKey(params.IpAddress))
+	}
+	if len(keys) == 0 {
+		respondWithError(w, 400, "email or ip_address is required")
+		return
+	}
+
+	cleared, err := cfg.db.ClearLoginFailures(r.Context(), keys)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if cleared == 0 {
+		respondWithError(w, 404, "No failed logins found")
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/totp"
+)
+
+const (
+	mfaChallengeDuration = 5 * time.Minute
+	mfaChallengeAttempts = 5
+	recoveryCodeCount    = 10
+	// Codes from one step either side of the current one are accepted to
+	// allow for clock drift between the server and the user's device.
+	totpSkew = 1
+)
+
+type mfaCodeParams struct {
+	Code string `json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// startMFAChallenge is the first half of signing in with two-factor
+// authentication: the password was right, and the client gets a short-lived
+// token to present along with a code to POST /api/login/mfa.
+func (cfg *apiConfig) startMFAChallenge(w http.ResponseWriter, r *http.Request, user database.User) {
+	token, hash := auth.MakeOneTimeToken(cfg.secret)
+	expiresAt := time.Now().Add(mfaChallengeDuration)
+	if err := cfg.db.CreateMFAChallenge(r.Context(), database.CreateMFAChallengeParams{TokenHash: hash, UserID: user.ID, ExpiresAt: expiresAt}); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 202, struct {
+		MFARequired bool      `json:"mfa_required"`
+		MFAToken    string    `json:"mfa_token"`
+		ExpiresAt   time.Time `json:"expires_at"`
+	}{MFARequired: true, MFAToken: token, ExpiresAt: expiresAt})
+}
+
+func (cfg *apiConfig) loginMFAHandler(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	hash, err := auth.VerifyOneTimeToken(params.MFAToken, cfg.secret)
+	if err != nil {
+		respondWithError(w, 401, "Invalid or expired MFA challenge")
+		return
+	}
+	userID, err := cfg.db.AttemptMFAChallenge(r.Context(), database.AttemptMFAChallengeParams{TokenHash: hash, Attempts: mfaChallengeAttempts})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 401, "Invalid or expired MFA challenge")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	// Codes count against the same limits as passwords, so that opening
+	// new challenges doesn't buy more guesses.
+	attempt, ok := cfg.beginLoginAttempt(w, r, user.Email)
+	if !ok {
+		return
+	}
+	ok, err = cfg.checkSecondFactor(r.Context(), user, params.Code)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !ok {
+		cfg.respondLoginFailed(w, r, attempt, uuid.NullUUID{UUID: user.ID, Valid: true}, "Invalid code")
+		return
+	}
+	consumed, err := cfg.db.ConsumeMFAChallenge(r.Context(), hash)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if consumed == 0 {
+		respondWithError(w, 401, "Invalid or expired MFA challenge")
+		return
+	}
+	cfg.loginSucceeded(r, user.Email)
+	cfg.respondWithSession(w, r, user)
+}
+
+// checkSecondFactor accepts a current TOTP code that hasn't been used
+// before, or one of the user's unused recovery codes.
+func (cfg *apiConfig) checkSecondFactor(ctx context.Context, user database.User, code string) (bool, error) {
+	if !user.TotpEnabledAt.Valid {
+		return false, nil
+	}
+	if step, ok := totp.Validate(user.TotpSecret.String, code, time.Now(), totpSkew); ok {
+		used, err := cfg.db.UseTOTPStep(ctx, database.UseTOTPStepParams{TotpLastStep: step, ID: user.ID})
+		return used == 1, err
+	}
+	used, err := cfg.db.UseRecoveryCode(ctx, database.UseRecoveryCodeParams{
+		CodeHash: auth.HashOneTimeToken(totp.NormalizeRecoveryCode(code)),
+		UserID:   user.ID,
+	})
+	return used == 1, err
+}
+
+// replaceRecoveryCodes issues a fresh set of recovery codes, invalidating
+// the previous ones. Only their hashes are stored, so this is the only time
+// the codes can be shown.
+func (cfg *apiConfig) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := totp.GenerateRecoveryCodes(recoveryCodeCount)
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashOneTimeToken(code)
+	}
+	if err := cfg.db.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := cfg.db.CreateRecoveryCodes(ctx, database.CreateRecoveryCodesParams{CodeHashes: hashes, UserID: userID}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (cfg *apiConfig) enrollTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	secret := totp.GenerateSecret()
+	updated, err := cfg.db.SetPendingTOTPSecret(r.Context(), database.SetPendingTOTPSecretParams{
+		TotpSecret: sql.NullString{String: secret, Valid: true},
+		ID:         userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if updated == 0 {
+		respondWithError(w, 409, "Two-factor authentication is already enabled")
+		return
+	}
+	respondWithJSON(w, 200, struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	}{Secret: secret, ProvisioningURI: totp.ProvisioningURI(secret, "Chirpy", user.Email)})
+}
+
+func (cfg *apiConfig) confirmTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	var params mfaCodeParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, 409, "Two-factor authentication is already enabled")
+		return
+	}
+	if !user.TotpSecret.Valid {
+		respondWithError(w, 400, "Two-factor enrollment has not been started")
+		return
+	}
+
+	step, ok := totp.Validate(user.TotpSecret.String, params.Code, time.Now(), totpSkew)
+	if !ok {
+		respondWithError(w, 400, "Invalid code")
+		return
+	}
+	enabled, err := cfg.db.EnableTOTP(r.Context(), database.EnableTOTPParams{TotpLastStep: step, ID: userID})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if enabled == 0 {
+		respondWithError(w, 409, "Two-factor authentication is already enabled")
+		return
+	}
+	codes, err := cfg.replaceRecoveryCodes(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err := cfg.recordSecurityEvent(r, userID, "totp_enabled"); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (cfg *apiConfig) disableTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	var params mfaCodeParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, 400, "Two-factor authentication is not enabled")
+		return
+	}
+	ok, err := cfg.checkSecondFactor(r.Context(), user, params.Code)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !ok {
+		respondWithError(w, 403, "Invalid code")
+		return
+	}
+
+	if err := cfg.db.DisableTOTP(r.Context(), userID); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err := cfg.db.DeleteRecoveryCodes(r.Context(), userID); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err := cfg.recordSecurityEvent(r, userID, "totp_disabled"); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	var params mfaCodeParams
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, 400, "Two-factor authentication is not enabled")
+		return
+	}
+	ok, err := cfg.checkSecondFactor(r.Context(), user, params.Code)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !ok {
+		respondWithError(w, 403, "Invalid code")
+		return
+	}
+
+	codes, err := cfg.replaceRecoveryCodes(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, recoveryCodesResponse{RecoveryCodes: codes})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/moderation"
+)
+
+var defaultModerationTerms = []moderation.Term{
+	{Term: "kerfuffle", Action: moderation.ActionMask},
+	{Term: "sharbert", Action: moderation.ActionMask},
+	{Term: "fornax", Action: moderation.ActionMask},
+}
+
+// reloadModerationTerms rebuilds the moderation filter from the terms loaded
+// at startup overlaid with the ones managed through the admin API.
+func (cfg *apiConfig) reloadModerationTerms(ctx context.Context) error {
+	managed, err := cfg.db.ListModerationTerms(ctx)
+	if err != nil {
+		return err
+	}
+	terms := slices.Clone(cfg.baseModerationTerms)
+	for _, t := range managed {
+		terms = append(terms, moderation.Term{Term: t.Term, Action: moderation.Action(t.Action)})
+	}
+	cfg.moderator.SetTerms(terms)
+	return nil
+}
+
+func (cfg *apiConfig) getModerationTermsHandler(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, 200, cfg.moderator.Terms())
+}
+
+func (cfg *apiConfig) putModerationTermHandler(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Term   string `json:"term"`
+		Action string `json:"action"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	term, err := moderation.ParseTerm(params.Term)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	action, err := moderation.ParseAction(params.Action)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	saved, err := cfg.db.UpsertModerationTerm(r.Context(), database.UpsertModerationTermParams{Term: term, Action: string(action)})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err := cfg.reloadModerationTerms(r.Context()); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, saved)
+}
+
+func (cfg *apiConfig) deleteModerationTermHandler(w http.ResponseWriter, r *http.Request) {
+	deleted, err := cfg.db.DeleteModerationTerm(r.Context(), moderation.Normalize(r.PathValue("term")))
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 404, "Term not found")
+		return
+	}
+	if err := cfg.reloadModerationTerms(r.Context()); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) getModerationFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	flags, err := cfg.db.ListUnresolvedModerationFlags(r.Context())
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if flags == nil {
+		flags = []database.ModerationFlag{}
+	}
+	respondWithJSON(w, 200, flags)
+}
+
+func (cfg *apiConfig) resolveModerationFlagHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("flagID")
+	idParam, _ := uuid.Parse(id)
+	resolved, err := cfg.db.ResolveModerationFlag(r.Context(), idParam)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if resolved == 0 {
+		respondWithError(w, 404, "Flag not found")
+		return
+	}
+	respondWithError(w, 204, "")
+}
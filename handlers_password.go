@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/mailer"
+)
+
+const passwordResetDuration = time.Hour
+
+// forgotPasswordHandler answers the same way, and just as quickly, whether
+// or not the address belongs to an account: everything past validating the
+// address happens in the background, so the response doesn't reveal who has
+// signed up.
+func (cfg *apiConfig) forgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Email string `json:"email"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	email, err := mailer.ValidateAddress(params.Email)
+	if err != nil {
+		respondWithError(w, 400, "Invalid email address")
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := cfg.sendPasswordResetEmail(ctx, email); err != nil {
+			log.Printf("Error sending password reset email: %s", err)
+		}
+	}()
+	respondWithError(w, 202, "")
+}
+
+func (cfg *apiConfig) sendPasswordResetEmail(ctx context.Context, email string) error {
+	user, err := cfg.db.GetUserFromEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	latest, err := cfg.db.GetLatestPasswordResetTime(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if latest.Valid && time.Since(latest.Time) < cfg.passwordResetInterval {
+		return nil
+	}
+
+	if err := cfg.db.DeleteUnusedPasswordResetTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	token, hash := auth.MakeOneTimeToken(cfg.secret)
+	if err := cfg.db.CreatePasswordResetToken(ctx, database.CreatePasswordResetTokenParams{
+		TokenHash: hash,
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(passwordResetDuration),
+	}); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Reset your Chirpy password with this code:\n\n%s\n", token)
+	if len(cfg.passwordResetURL) != 0 {
+		body = fmt.Sprintf("Reset your Chirpy password by opening this link:\n\n%s\n", linkWithToken(cfg.passwordResetURL, token))
+	}
+	cfg.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body:    body + "\nIt expires in an hour and can only be used once. If you didn't ask to reset your password, you can ignore this email.\n",
+	})
+	return nil
+}
+
+func (cfg *apiConfig) resetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if len(params.Password) == 0 {
+		respondWithError(w, 400, "Password is required")
+		return
+	}
+	hash, err := auth.VerifyOneTimeToken(params.Token, cfg.secret)
+	if err != nil {
+		respondWithError(w, 400, "Invalid or expired reset token")
+		return
+	}
+
+	hashedPass, err := auth.HashPassword(params.Password)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	user, err := cfg.db.ResetPassword(r.Context(), database.ResetPasswordParams{TokenHash: hash, HashedPassword: hashedPass})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 400, "Invalid or expired reset token")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	if err := cfg.revokeAllTokens(r.Context(), user.ID); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err := cfg.recordSecurityEvent(r, user.ID, "password_reset"); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	cfg.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Your Chirpy password was changed",
+		Body:    "Your Chirpy password was just reset and you have been signed out everywhere, and your API tokens have been revoked. If this wasn't you, reset your password again right away.\n",
+	})
+	respondWithError(w, 204, "")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+const maxWebhookBodySize = 1 << 20
+
+var polkaEvents = []string{"user.upgraded", "user.renewed", "user.downgraded", "payment.failed"}
+
+type polkaEvent struct {
+	Event string `json:"event"`
+	Data  struct {
+		UserID      string     `json:"user_id"`
+		Plan        string     `json:"plan"`
+		PeriodStart *time.Time `json:"period_start"`
+		PeriodEnd   *time.Time `json:"period_end"`
+	} `json:"data"`
+}
+
+// webhookResult is how a webhook delivery was handled: the response Polka
+// gets, and the outcome and underlying error recorded in the delivery log.
+type webhookResult struct {
+	status  int
+	message string
+	outcome string
+	err     error
+	event   string
+	// verified is set once the delivery's signature has been checked.
+	verified bool
+}
+
+func webhookProcessed(outcome string) webhookResult {
+	return webhookResult{status: 204, outcome: outcome}
+}
+
+func webhookRejected(status int, message string, err error) webhookResult {
+	if err == nil {
+		err = errors.New(message)
+	}
+	return webhookResult{status: status, message: message, outcome: "rejected", err: err}
+}
+
+func webhookFailed(err error) webhookResult {
+	return webhookResult{status: 500, message: "Something went wrong", outcome: "failed", err: err}
+}
+
+func (cfg *apiConfig) polkaWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	receivedAt := time.Now()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	var result webhookResult
+	if err != nil {
+		result = webhookRejected(400, "Invalid request body", err)
+	} else {
+		result = cfg.processPolkaWebhook(r.Context(), r.Header, body, receivedAt)
+	}
+
+	if _, err := cfg.logWebhookDelivery(r.Context(), r.Header, body, receivedAt, result, uuid.NullUUID{}); err != nil {
+		log.Printf("Error logging Polka webhook: %s", err)
+	}
+	respondWithError(w, result.status, result.message)
+}
+
+// processPolkaWebhook verifies and applies one Polka delivery. Replays go
+// through it too, verified as of the time the delivery first arrived.
+func (cfg *apiConfig) processPolkaWebhook(ctx context.Context, header http.Header, body []byte, receivedAt time.Time) webhookResult {
+	var event polkaEvent
+	// The event is read before verifying only so that rejected deliveries
+	// can be found by event in the log.
+	eventErr := json.Unmarshal(body, &event)
+
+	delivery, err := cfg.polka.VerifyAt(header, body, receivedAt)
+	if err != nil {
+		log.Printf("Rejected Polka webhook: %s", err)
+		result := webhookRejected(401, "Unauthorized", err)
+		result.event = event.Event
+		return result
+	}
+
+	result := cfg.applyPolkaEvent(ctx, delivery.ID, event, eventErr)
+	result.event = event.Event
+	result.verified = true
+	return result
+}
+
+func (cfg *apiConfig) applyPolkaEvent(ctx context.Context, deliveryID string, event polkaEvent, eventErr error) webhookResult {
+	if eventErr != nil {
+		return webhookRejected(400, "Invalid request body", eventErr)
+	}
+	if !slices.Contains(polkaEvents, event.Event) {
+		return webhookRejected(400, "Unknown event", nil)
+	}
+	userID, err := uuid.Parse(event.Data.UserID)
+	if err != nil {
+		return webhookRejected(404, "User not found", err)
+	}
+
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return webhookFailed(err)
+	}
+	defer tx.Rollback()
+	queries := cfg.db.WithTx(tx)
+
+	// Recording the delivery in the same transaction makes retries and
+	// replays of one that has already been handled do nothing, and lets a
+	// retry of one that failed try again.
+	recorded, err := queries.CreateWebhookEvent(ctx, database.CreateWebhookEventParams{DeliveryID: deliveryID, Event: event.Event})
+	if err != nil {
+		return webhookFailed(err)
+	}
+	if recorded == 0 {
+		return webhookProcessed("duplicate")
+	}
+
+	err = cfg.applySubscriptionEvent(ctx, queries, userID, event, deliveryID)
+	switch {
+	case errors.Is(err, errUserNotFound):
+		return webhookRejected(404, "User not found", err)
+	case errors.Is(err, errSubscriptionNotFound):
+		return webhookRejected(404, "Subscription not found", err)
+	case err != nil:
+		return webhookFailed(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return webhookFailed(err)
+	}
+	return webhookProcessed("processed")
+}
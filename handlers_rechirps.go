@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+// createRechirp reposts params.RechirpOf for params.UserID. Rechirping the same
+// chirp twice returns the existing rechirp instead of creating another.
+func (cfg *apiConfig) createRechirp(w http.ResponseWriter, r *http.Request, params database.CreateChirpParams) {
+	if len(params.Body) != 0 || params.InReplyTo.Valid || params.QuoteOf.Valid {
+		respondWithError(w, 400, "A rechirp cannot have a body, reply or quote")
+		return
+	}
+	original, err := cfg.originalChirp(r.Context(), params.RechirpOf.UUID)
+	if err != nil {
+		respondWithError(w, 400, "Rechirped chirp does not exist")
+		return
+	}
+	params.RechirpOf.UUID = original.ID
+
+	existingParams := database.GetRechirpParams{UserID: params.UserID, RechirpOf: params.RechirpOf}
+	if existing, err := cfg.db.GetRechirp(r.Context(), existingParams); err == nil {
+		cfg.respondWithChirp(w, r, 200, params.UserID, existing)
+		return
+	}
+	newChirp, err := cfg.db.CreateChirp(r.Context(), params)
+	if err != nil {
+		// A concurrent request for the same rechirp may have won the unique index.
+		if existing, err := cfg.db.GetRechirp(r.Context(), existingParams); err == nil {
+			cfg.respondWithChirp(w, r, 200, params.UserID, existing)
+			return
+		}
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	cfg.respondWithChirp(w, r, 201, params.UserID, newChirp)
+}
+
+// originalChirp looks up a chirp that is about to be rechirped or quoted,
+// following a rechirp through to the chirp it reposts.
+func (cfg *apiConfig) originalChirp(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.db.GetChirpFromID(ctx, id)
+	if err != nil {
+		return chirp, err
+	}
+	if chirp.RechirpOf.Valid {
+		if chirp, err = cfg.db.GetChirpFromID(ctx, chirp.RechirpOf.UUID); err != nil {
+			return chirp, err
+		}
+	}
+	if chirp.DeletedAt.Valid {
+		return chirp, fmt.Errorf("chirp %s has been deleted", chirp.ID)
+	}
+	return chirp, nil
+}
+
+func (cfg *apiConfig) respondWithChirp(w http.ResponseWriter, r *http.Request, code int, viewer uuid.UUID, chirp database.Chirp) {
+	responses, err := cfg.chirpResponses(r.Context(), uuid.NullUUID{UUID: viewer, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, code, responses[0])
+}
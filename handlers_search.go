@@ -0,0 +1,137 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+type searchResult struct {
+	chirpResponse
+	Rank float64 `json:"rank"`
+	// Snippet is HTML: the escaped chirp body with matches in <mark> tags.
+	Snippet string `json:"snippet"`
+}
+
+func (cfg *apiConfig) searchChirpsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params, err := parseSearchParams(query)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	limit := params.RowLimit
+	params.RowLimit++
+
+	rows, err := cfg.db.SearchChirps(r.Context(), params)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	more := len(rows) > int(limit)
+	if more {
+		rows = rows[:limit]
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, database.Chirp{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			Body:      row.Body,
+			UserID:    row.UserID,
+			InReplyTo: row.InReplyTo,
+			DeletedAt: row.DeletedAt,
+			RechirpOf: row.RechirpOf,
+			QuoteOf:   row.QuoteOf,
+		})
+	}
+	responses, err := cfg.chirpResponses(r.Context(), cfg.viewerFromRequest(r), chirps)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	results := make([]searchResult, 0, len(rows))
+	for i, row := range rows {
+		results = append(results, searchResult{chirpResponse: responses[i], Rank: row.Rank, Snippet: row.Snippet})
+	}
+
+	if more {
+		query.Set("offset", strconv.Itoa(int(params.RowOffset+limit)))
+		next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+	respondWithJSON(w, 200, results)
+}
+
+func parseSearchParams(query url.Values) (database.SearchChirpsParams, error) {
+	params := database.SearchChirpsParams{
+		Query:       strings.TrimSpace(query.Get("q")),
+		OrderByRank: true,
+		RowLimit:    defaultPageLimit,
+	}
+	if len(params.Query) == 0 {
+		return params, fmt.Errorf("q is required")
+	}
+
+	switch query.Get("order") {
+	case "", "relevance":
+	case "recency":
+		params.OrderByRank = false
+	default:
+		return params, fmt.Errorf("order must be relevance or recency")
+	}
+
+	if author := query.Get("author_id"); len(author) != 0 {
+		userID, err := uuid.Parse(author)
+		if err != nil {
+			return params, fmt.Errorf("invalid author_id")
+		}
+		params.AuthorID = uuid.NullUUID{UUID: userID, Valid: true}
+	}
+
+	var err error
+	if params.Since, err = parseSearchTime(query.Get("since")); err != nil {
+		return params, fmt.Errorf("invalid since: %w", err)
+	}
+	if params.Until, err = parseSearchTime(query.Get("until")); err != nil {
+		return params, fmt.Errorf("invalid until: %w", err)
+	}
+
+	if limit := query.Get("limit"); len(limit) != 0 {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return params, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		params.RowLimit = int32(n)
+	}
+	if offset := query.Get("offset"); len(offset) != 0 {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return params, fmt.Errorf("offset must be a non-negative integer")
+		}
+		params.RowOffset = int32(n)
+	}
+	return params, nil
+}
+
+// parseSearchTime accepts either a full RFC 3339 timestamp or a plain date.
+func parseSearchTime(value string) (sql.NullTime, error) {
+	if len(value) == 0 {
+		return sql.NullTime{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return sql.NullTime{Time: t, Valid: true}, nil
+		}
+	}
+	return sql.NullTime{}, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) getSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	sessions, err := cfg.db.ListSessions(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if sessions == nil {
+		sessions = []database.ListSessionsRow{}
+	}
+	respondWithJSON(w, 200, sessions)
+}
+
+func (cfg *apiConfig) deleteSessionHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	id := r.PathValue("sessionID")
+	idParam, _ := uuid.Parse(id)
+	revoked, err := cfg.db.RevokeSession(r.Context(), database.RevokeSessionParams{FamilyID: idParam, UserID: userID})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if revoked == 0 {
+		respondWithError(w, 404, "Session not found")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+// deleteSessionsHandler logs the user out everywhere: every refresh token and
+// API token is revoked and bumping the token version invalidates every
+// access token issued so far, including the one used for this request.
+func (cfg *apiConfig) deleteSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	if err := cfg.revokeAllTokens(r.Context(), userID); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
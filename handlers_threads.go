@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type threadNode struct {
+	chirpResponse
+	Replies []threadNode `json:"replies"`
+}
+
+func (cfg *apiConfig) threadHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("chirpID")
+	idParam, _ := uuid.Parse(id)
+	chirp, err := cfg.db.GetChirpFromID(r.Context(), idParam)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	ancestors, err := cfg.db.GetChirpAncestors(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	descendants, err := cfg.db.GetChirpDescendants(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	all := append(append(ancestors, chirp), descendants...)
+	responses, err := cfg.chirpResponses(r.Context(), cfg.viewerFromRequest(r), all)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	// Descendants arrive oldest first, so each reply list is built in order.
+	children := make(map[uuid.UUID][]chirpResponse)
+	for _, resp := range responses[len(ancestors)+1:] {
+		children[*resp.InReplyTo] = append(children[*resp.InReplyTo], resp)
+	}
+	var build func(resp chirpResponse) threadNode
+	build = func(resp chirpResponse) threadNode {
+		node := threadNode{chirpResponse: resp, Replies: []threadNode{}}
+		for _, child := range children[resp.ID] {
+			node.Replies = append(node.Replies, build(child))
+		}
+		return node
+	}
+
+	thread := struct {
+		Ancestors []chirpResponse `json:"ancestors"`
+		Chirp     threadNode      `json:"chirp"`
+	}{
+		Ancestors: responses[:len(ancestors)],
+		Chirp:     build(responses[len(ancestors)]),
+	}
+	respondWithJSON(w, 200, thread)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+const maxAPITokenNameLength = 100
+
+type apiTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Token      string     `json:"token,omitempty"`
+}
+
+func (cfg *apiConfig) getAPITokensHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	tokens, err := cfg.db.ListAPITokens(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	responses := make([]apiTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, apiTokenResponse{
+			ID:         token.ID,
+			Name:       token.Name,
+			Scopes:     token.Scopes,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  nullTimePtr(token.ExpiresAt),
+			LastUsedAt: nullTimePtr(token.LastUsedAt),
+		})
+	}
+	respondWithJSON(w, 200, responses)
+}
+
+func (cfg *apiConfig) createAPITokenHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	var params struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if len(name) == 0 || len(name) > maxAPITokenNameLength {
+		respondWithError(w, 400, "Name must be between 1 and 100 characters")
+		return
+	}
+	scopes, err := auth.ParseScopes(params.Scopes)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	if len(scopes) == 0 {
+		respondWithError(w, 400, "At least one scope is required")
+		return
+	}
+	var expiresAt sql.NullTime
+	if params.ExpiresAt != nil {
+		if !params.ExpiresAt.After(time.Now()) {
+			respondWithError(w, 400, "expires_at must be in the future")
+			return
+		}
+		expiresAt = sql.NullTime{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	token, hash := auth.MakeAPIToken()
+	created, err := cfg.db.CreateAPIToken(r.Context(), database.CreateAPITokenParams{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err := cfg.recordSecurityEvent(r, userID, "api_token_created"); err != nil {
+		log.Printf("Error recording security event: %s", err)
+	}
+
+	respondWithJSON(w, 201, apiTokenResponse{
+		ID:         created.ID,
+		Name:       created.Name,
+		Scopes:     created.Scopes,
+		CreatedAt:  created.CreatedAt,
+		ExpiresAt:  nullTimePtr(created.ExpiresAt),
+		LastUsedAt: nullTimePtr(created.LastUsedAt),
+		Token:      token,
+	})
+}
+
+func (cfg *apiConfig) deleteAPITokenHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticateSession(r)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	tokenID, err := uuid.Parse(r.PathValue("tokenID"))
+	if err != nil {
+		respondWithError(w, 404, "Token not found")
+		return
+	}
+	revoked, err := cfg.db.RevokeAPIToken(r.Context(), database.RevokeAPITokenParams{ID: tokenID, UserID: userID})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if revoked == 0 {
+		respondWithError(w, 404, "Token not found")
+		return
+	}
+	if err := cfg.recordSecurityEvent(r, userID, "api_token_revoked"); err != nil {
+		log.Printf("Error recording security event: %s", err)
+	}
+	w.WriteHeader(204)
+}
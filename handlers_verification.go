@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/mailer"
+)
+
+const emailVerificationDuration = 24 * time.Hour
+
+// sendVerificationEmail issues a verification token for the user's address,
+// replacing any that are still unused, and mails it.
+func (cfg *apiConfig) sendVerificationEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	if err := cfg.db.DeleteUnusedEmailVerificationTokens(ctx, userID); err != nil {
+		return err
+	}
+	token, hash := auth.MakeOneTimeToken(cfg.secret)
+	if err := cfg.db.CreateEmailVerificationToken(ctx, database.CreateEmailVerificationTokenParams{
+		TokenHash: hash,
+		UserID:    userID,
+		Email:     email,
+		ExpiresAt: time.Now().Add(emailVerificationDuration),
+	}); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Confirm your Chirpy email address with this verification code:\n\n%s\n", token)
+	if len(cfg.verifyEmailURL) != 0 {
+		body = fmt.Sprintf("Confirm your Chirpy email address by opening this link:\n\n%s\n", linkWithToken(cfg.verifyEmailURL, token))
+	}
+	cfg.sendMail(mailer.Message{
+		To:      email,
+		Subject: "Verify your Chirpy email address",
+		Body:    body + "\nIt expires in 24 hours. If you didn't sign up for Chirpy, you can ignore this email.\n",
+	})
+	return nil
+}
+
+func (cfg *apiConfig) verifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Token string `json:"token"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&params); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	hash, err := auth.VerifyOneTimeToken(params.Token, cfg.secret)
+	if err != nil {
+		respondWithError(w, 400, "Invalid or expired verification token")
+		return
+	}
+	if _, err := cfg.db.VerifyEmail(r.Context(), hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 400, "Invalid or expired verification token")
+			return
+		}
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 204, "")
+}
+
+func (cfg *apiConfig) resendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeAccountWrite)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	user, err := cfg.db.GetUserFromID(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, 409, "Email address is already verified")
+		return
+	}
+
+	latest, err := cfg.db.GetLatestEmailVerificationTime(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if latest.Valid {
+		if wait := cfg.verificationResendInterval - time.Since(latest.Time); wait > 0 {
+			setRetryAfter(w, wait)
+			respondWithError(w, 429, "Verification email was sent recently, try again later")
+			return
+		}
+	}
+
+	if err := cfg.sendVerificationEmail(r.Context(), user.ID, user.Email); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithError(w, 202, "")
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+type webhookDeliveryResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	Source     string          `json:"source"`
+	DeliveryID string          `json:"delivery_id"`
+	Event      string          `json:"event"`
+	ReceivedAt time.Time       `json:"received_at"`
+	StatusCode int32           `json:"status_code"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error"`
+	ReplayOf   *uuid.UUID      `json:"replay_of"`
+	Headers    json.RawMessage `json:"headers,omitempty"`
+	Body       *string         `json:"body,omitempty"`
+}
+
+// newWebhookDeliveryResponse describes a logged delivery, with its headers
+// and body only when full is set.
+func newWebhookDeliveryResponse(delivery database.WebhookDelivery, full bool) webhookDeliveryResponse {
+	response := webhookDeliveryResponse{
+		ID:         delivery.ID,
+		Source:     delivery.Source,
+		DeliveryID: delivery.DeliveryID,
+		Event:      delivery.Event,
+		ReceivedAt: delivery.ReceivedAt,
+		StatusCode: delivery.StatusCode,
+		Outcome:    delivery.Outcome,
+		Error:      delivery.Error,
+	}
+	if delivery.ReplayOf.Valid {
+		response.ReplayOf = &delivery.ReplayOf.UUID
+	}
+	if full {
+		body := string(delivery.Body)
+		response.Headers = delivery.Headers
+		response.Body = &body
+	}
+	return response
+}
+
+func (cfg *apiConfig) adminListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, err := parsePageRequest(query, true)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	var outcome sql.NullString
+	if name := query.Get("outcome"); len(name) != 0 {
+		if !slices.Contains(webhookOutcomes, name) {
+			respondWithError(w, 400, "outcome must be processed, duplicate, rejected or failed")
+			return
+		}
+		outcome = sql.NullString{String: name, Valid: true}
+	}
+
+	deliveryCursor := func(d database.WebhookDelivery) pageCursor { return pageCursor{CreatedAt: d.ReceivedAt, ID: d.ID} }
+	deliveries, next, prev, err := fetchPage(page, deliveryCursor, func(ascending bool, cursor *pageCursor, limit int32) ([]database.WebhookDelivery, error) {
+		receivedAt, id := cursorArgs(cursor)
+		params := database.ListWebhookDeliveriesAfterParams{CursorCreatedAt: receivedAt, CursorID: id, Outcome: outcome, RowLimit: limit}
+		if ascending {
+			return cfg.db.ListWebhookDeliveriesAfter(r.Context(), params)
+		}
+		return cfg.db.ListWebhookDeliveriesBefore(r.Context(), database.ListWebhookDeliveriesBeforeParams(params))
+	})
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	responses := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, newWebhookDeliveryResponse(delivery, false))
+	}
+	setPageLinks(w, r, next, prev)
+	respondWithJSON(w, 200, responses)
+}
+
+func (cfg *apiConfig) webhookDeliveryFromPath(r *http.Request) (database.WebhookDelivery, error) {
+	id, err := uuid.Parse(r.PathValue("webhookID"))
+	if err != nil {
+		return database.WebhookDelivery{}, sql.ErrNoRows
+	}
+	return cfg.db.GetWebhookDelivery(r.Context(), id)
+}
+
+func (cfg *apiConfig) adminGetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	delivery, err := cfg.webhookDeliveryFromPath(r)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Webhook not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, newWebhookDeliveryResponse(delivery, true))
+}
+
+// adminReplayWebhookHandler runs a logged delivery through the webhook
+// handling again and logs the result as a replay of the original delivery.
+// Deliveries that were already processed are reported as duplicates.
+func (cfg *apiConfig) adminReplayWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	delivery, err := cfg.webhookDeliveryFromPath(r)
+	if err == nil && delivery.ReplayOf.Valid {
+		// Replaying a replay replays the original, so that its signature is
+		// checked as of when Polka sent it.
+		delivery, err = cfg.db.GetWebhookDelivery(r.Context(), delivery.ReplayOf.UUID)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 404, "Webhook not found")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if delivery.Source != polkaWebhookSource {
+		respondWithError(w, 400, "Webhooks from this source can't be replayed")
+		return
+	}
+
+	var header http.Header
+	if err := json.Unmarshal(delivery.Headers, &header); err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	result := cfg.processPolkaWebhook(r.Context(), header, delivery.Body, delivery.ReceivedAt)
+	replay, err := cfg.logWebhookDelivery(r.Context(), header, delivery.Body, time.Now(), result, uuid.NullUUID{UUID: delivery.ID, Valid: true})
+	if err != nil {
+		log.Printf("Error logging Polka webhook replay: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 201, newWebhookDeliveryResponse(replay, true))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+// The patterns are mirrored by the backfill in
+// sql/schema/026_hashtag_mention_backfill.sql. Users don't have handles yet,
+// so mentions are stored as the handle written, unresolved.
+var (
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])#([\p{L}\p{N}_]*\p{L}[\p{L}\p{N}_]*)`)
+	mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_]{1,30})`)
+)
+
+func extractHashtags(body string) []string {
+	return extractEntities(hashtagPattern, body)
+}
+
+func extractMentions(body string) []string {
+	return extractEntities(mentionPattern, body)
+}
+
+func extractEntities(pattern *regexp.Regexp, body string) []string {
+	seen := make(map[string]bool)
+	var entities []string
+	for _, match := range pattern.FindAllStringSubmatch(body, -1) {
+		entity := strings.ToLower(match[1])
+		if !seen[entity] {
+			seen[entity] = true
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+// indexChirpEntities replaces the hashtags and mentions recorded for a chirp
+// with the ones found in its current body.
+func (cfg *apiConfig) indexChirpEntities(ctx context.Context, chirp database.Chirp) error {
+	if err := cfg.db.DeleteChirpHashtags(ctx, chirp.ID); err != nil {
+		return err
+	}
+	if err := cfg.db.DeleteChirpMentions(ctx, chirp.ID); err != nil {
+		return err
+	}
+	if chirp.DeletedAt.Valid {
+		return nil
+	}
+
+	if tags := extractHashtags(chirp.Body); len(tags) != 0 {
+		params := database.AddChirpHashtagsParams{ChirpID: chirp.ID, Tags: tags, CreatedAt: chirp.CreatedAt}
+		if err := cfg.db.AddChirpHashtags(ctx, params); err != nil {
+			return err
+		}
+	}
+	if handles := extractMentions(chirp.Body); len(handles) != 0 {
+		params := database.AddChirpMentionsParams{ChirpID: chirp.ID, Handles: handles, CreatedAt: chirp.CreatedAt}
+		if err := cfg.db.AddChirpMentions(ctx, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"regexp"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func respondWithError(w http.ResponseWriter, code int, msg string) {
@@ -43,17 +48,65 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(jsonData)
 }
 
-func replaceProfane(s string) string {
-	re := regexp.MustCompile(`kerfuffle|sharbert|fornax`)
-	bodyString := strings.Fields(s)
-	var cleanedBody []string
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
 
-	for _, word := range bodyString {
-		if re.MatchString(strings.ToLower(word)) {
-			cleanedBody = append(cleanedBody, "****")
-		} else {
-			cleanedBody = append(cleanedBody, word)
+func boolFromEnv(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func intFromEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// listFromEnv splits a comma-separated variable, ignoring empty entries.
+func listFromEnv(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			list = append(list, item)
 		}
 	}
-	return strings.Join(cleanedBody, " ")
+	return list
+}
+
+// nullTimePtr turns a nullable column into a value that encodes as null.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
 }
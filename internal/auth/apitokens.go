@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// APITokenPrefix starts every personal API token, which tells them apart
+// from access JWTs and makes leaked tokens easy to scan for.
+const APITokenPrefix = "chirpy_pat_"
+
+// Scope is a permission carried by a personal API token.
+type Scope string
+
+const (
+	ScopeChirpsRead   Scope = "chirps:read"
+	ScopeChirpsWrite  Scope = "chirps:write"
+	ScopeAccountRead  Scope = "account:read"
+	ScopeAccountWrite Scope = "account:write"
+)
+
+var Scopes = []Scope{ScopeChirpsRead, ScopeChirpsWrite, ScopeAccountRead, ScopeAccountWrite}
+
+// MakeAPIToken returns a new personal API token and the hash to store it
+// under. The token itself is only ever shown to its owner once.
+func MakeAPIToken() (token, hash string) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(key)
+	return token, HashAPIToken(token)
+}
+
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+func HashAPIToken(token string) string {
+	return HashOneTimeToken(token)
+}
+
+// ParseScopes checks that every name is a known scope and returns them
+// sorted without duplicates.
+func ParseScopes(names []string) ([]string, error) {
+	scopes := make([]string, 0, len(names))
+	for _, name := range names {
+		if !slices.Contains(Scopes, Scope(name)) {
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+		scopes = append(scopes, name)
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
+
+// HasScope reports whether a token's scopes include the one required.
+func HasScope(scopes []string, required Scope) bool {
+	return slices.Contains(scopes, string(required))
+}
@@ -0,0 +1,43 @@
+package auth_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func TestMakeAPIToken(t *testing.T) {
+	token, hash := auth.MakeAPIToken()
+	other, _ := auth.MakeAPIToken()
+	if token == other {
+		t.Fatal("expected distinct tokens")
+	}
+	if !auth.IsAPIToken(token) {
+		t.Errorf("token %q is missing the prefix", token)
+	}
+	if hash != auth.HashAPIToken(token) || hash == token {
+		t.Errorf("unexpected hash %q for %q", hash, token)
+	}
+	if auth.IsAPIToken("eyJhbGciOiJIUzI1NiJ9.e30.sig") {
+		t.Error("a JWT should not look like an API token")
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := auth.ParseScopes([]string{"chirps:write", "account:read", "chirps:write"})
+	if err != nil {
+		t.Fatalf("ParseScopes returned error: %v", err)
+	}
+	want := []string{"account:read", "chirps:write"}
+	if !reflect.DeepEqual(scopes, want) {
+		t.Errorf("ParseScopes = %v, want %v", scopes, want)
+	}
+	if !auth.HasScope(scopes, auth.ScopeChirpsWrite) || auth.HasScope(scopes, auth.ScopeChirpsRead) {
+		t.Errorf("HasScope gave wrong answers for %v", scopes)
+	}
+
+	if _, err := auth.ParseScopes([]string{"chirps:delete"}); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+}
@@ -102,3 +102,33 @@ func TestMakeJWTFields(t *testing.T) {
 		t.Errorf("expiresAt not within expected range: got %v, expected around %v", claims.ExpiresAt.Time, expectedExpiry)
 	}
 }
+
+func TestParseJWTVersionAndRole(t *testing.T) {
+	keys := auth.NewHMACKeyStore("versionsecret")
+	userID := uuid.New()
+
+	want := auth.AccessToken{UserID: userID, Version: 7, Role: auth.RoleModerator}
+	tokenString, err := keys.MakeJWT(want, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT returned error: %v", err)
+	}
+	accessToken, err := keys.ParseJWT(tokenString)
+	if err != nil {
+		t.Fatalf("ParseJWT returned error: %v", err)
+	}
+	if accessToken != want {
+		t.Errorf("expected %+v, got %+v", want, accessToken)
+	}
+
+	tokenString, err = auth.MakeJWT(userID, "versionsecret", time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT returned error: %v", err)
+	}
+	accessToken, err = keys.ParseJWT(tokenString)
+	if err != nil {
+		t.Fatalf("ParseJWT returned error: %v", err)
+	}
+	if accessToken.Version != 0 || len(accessToken.Role) != 0 {
+		t.Errorf("expected version 0 and no role for MakeJWT, got %+v", accessToken)
+	}
+}
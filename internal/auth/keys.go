@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	signKey any
+	public  any
+}
+
+// KeyStore signs access tokens with a single active key and verifies them
+// against every key it holds, so that a new signing key can be rolled out
+// while tokens signed with the previous one are still in circulation.
+type KeyStore struct {
+	signing    *key
+	keys       map[string]*key
+	validation ValidationOptions
+}
+
+// NewHMACKeyStore returns a key store that signs and verifies with HS256
+// using secret. HMAC keys have no ID and are never published in the JWKS.
+func NewHMACKeyStore(secret string) *KeyStore {
+	hmac := &key{method: jwt.SigningMethodHS256, signKey: []byte(secret), public: []byte(secret)}
+	return &KeyStore{signing: hmac, keys: map[string]*key{"": hmac}, validation: DefaultValidationOptions()}
+}
+
+// LoadKeyStore reads every .pem file in dir as a key whose ID is the file
+// name without the extension. Files holding a private key (PKCS #8, or
+// PKCS #1 for RSA) can sign; files holding only a public key verify tokens
+// signed by a retired key. Ed25519 keys sign with EdDSA and RSA keys with
+// RS256.
+//
+// The key named by signingKeyID signs new tokens; if it is empty, the
+// private key whose ID sorts last does. If hmacSecret is not empty, tokens
+// without a key ID are still accepted when signed with it, which lets
+// servers switch from a shared secret without logging everyone out.
+func LoadKeyStore(dir, signingKeyID, hmacSecret string) (*KeyStore, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := &KeyStore{keys: make(map[string]*key), validation: DefaultValidationOptions()}
+	if len(hmacSecret) != 0 {
+		ks.keys[""] = &key{method: jwt.SigningMethodHS256, public: []byte(hmacSecret)}
+	}
+	for _, path := range paths {
+		k, err := loadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ks.keys[k.id] = k
+		if k.signKey != nil && (len(signingKeyID) == 0 || k.id == signingKeyID) {
+			ks.signing = k
+		}
+	}
+	if ks.signing == nil {
+		if len(signingKeyID) != 0 {
+			return nil, fmt.Errorf("no private key %q in %s", signingKeyID, dir)
+		}
+		return nil, fmt.Errorf("no private keys in %s", dir)
+	}
+	return ks, nil
+}
+
+func loadKey(path string) (*key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+	k := &key{id: strings.TrimSuffix(filepath.Base(path), ".pem")}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if signer, ok := parsed.(crypto.Signer); ok {
+		k.signKey = signer
+		parsed = signer.Public()
+	}
+	switch public := parsed.(type) {
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+		k.public = public
+	case *rsa.PublicKey:
+		k.method = jwt.SigningMethodRS256
+		k.public = public
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	return k, nil
+}
+
+func (ks *KeyStore) MakeJWT(accessToken AccessToken, expiresIn time.Duration) (string, error) {
+	token := jwt.NewWithClaims(
+		ks.signing.method,
+		accessClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    ks.validation.Issuer,
+				Subject:   accessToken.UserID.String(),
+				Audience:  ks.validation.Audiences,
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn))},
+			Version: accessToken.Version,
+			Role:    accessToken.Role})
+	if len(ks.signing.id) != 0 {
+		token.Header["kid"] = ks.signing.id
+	}
+	signedString, err := token.SignedString(ks.signing.signKey)
+	if err != nil {
+		return "", err
+	}
+	return signedString, nil
+}
+
+func (ks *KeyStore) ParseJWT(tokenString string) (AccessToken, error) {
+	claims := &accessClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, ks.keyfunc, ks.parserOptions()...); err != nil {
+		return AccessToken{}, classifyError(err)
+	}
+	if err := ks.checkRequiredClaims(claims); err != nil {
+		return AccessToken{}, err
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("%w: invalid subject", ErrTokenInvalidClaims)
+	}
+	return AccessToken{UserID: userID, Version: claims.Version, Role: claims.Role}, nil
+}
+
+// keyfunc picks the verification key named by the token's kid header and
+// refuses tokens whose algorithm doesn't match that key, so a public key can
+// never be used as an HMAC secret.
+func (ks *KeyStore) keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return k.public, nil
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every asymmetric verification key.
+func (ks *KeyStore) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		jwk := JWK{KeyID: k.id, Use: "sig", Algorithm: k.method.Alg()}
+		switch public := k.public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(a, b int) bool { return set.Keys[a].KeyID < set.Keys[b].KeyID })
+	return set
+}
@@ -0,0 +1,258 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writePrivateKey(t *testing.T, dir, name string, key any) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, name, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, name string, key any) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, name, "PUBLIC KEY", der)
+}
+
+func headerOf(t *testing.T, tokenString string) map[string]any {
+	t.Helper()
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token.Header
+}
+
+func TestKeyStoreSignsWithLatestKey(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "2025-01", rsaKey)
+	writePrivateKey(t, dir, "2025-02", edKey)
+
+	keys, err := auth.LoadKeyStore(dir, "", "")
+	if err != nil {
+		t.Fatalf("LoadKeyStore returned error: %v", err)
+	}
+	userID := uuid.New()
+	tokenString, err := keys.MakeJWT(auth.AccessToken{UserID: userID, Version: 2}, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT returned error: %v", err)
+	}
+	header := headerOf(t, tokenString)
+	if header["kid"] != "2025-02" || header["alg"] != "EdDSA" {
+		t.Errorf("expected EdDSA token signed by 2025-02, got header %v", header)
+	}
+	accessToken, err := keys.ParseJWT(tokenString)
+	if err != nil {
+		t.Fatalf("ParseJWT returned error: %v", err)
+	}
+	if accessToken.UserID != userID || accessToken.Version != 2 {
+		t.Errorf("unexpected access token %+v", accessToken)
+	}
+
+	// Pinning the signing key selects RS256.
+	keys, err = auth.LoadKeyStore(dir, "2025-01", "")
+	if err != nil {
+		t.Fatalf("LoadKeyStore returned error: %v", err)
+	}
+	tokenString, err = keys.MakeJWT(auth.AccessToken{UserID: userID}, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT returned error: %v", err)
+	}
+	if header := headerOf(t, tokenString); header["kid"] != "2025-01" || header["alg"] != "RS256" {
+		t.Errorf("expected RS256 token signed by 2025-01, got header %v", header)
+	}
+	if _, err := keys.ParseJWT(tokenString); err != nil {
+		t.Errorf("ParseJWT returned error: %v", err)
+	}
+}
+
+func TestKeyStoreRollover(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	oldPublic, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, newPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, oldDir, "old", oldPrivate)
+	writePublicKey(t, newDir, "old", oldPublic)
+	writePrivateKey(t, newDir, "new", newPrivate)
+
+	oldKeys, err := auth.LoadKeyStore(oldDir, "", "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKeys, err := auth.LoadKeyStore(newDir, "", "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.New()
+
+	oldToken, err := oldKeys.MakeJWT(auth.AccessToken{UserID: userID}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newKeys.ParseJWT(oldToken); err != nil {
+		t.Errorf("token signed with retired key rejected: %v", err)
+	}
+
+	legacyToken, err := auth.MakeJWT(userID, "legacy", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newKeys.ParseJWT(legacyToken); err != nil {
+		t.Errorf("HMAC token rejected during migration: %v", err)
+	}
+
+	retiredKeys, err := auth.LoadKeyStore(newDir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := retiredKeys.ParseJWT(legacyToken); err == nil {
+		t.Error("expected HMAC token to be rejected once the HMAC secret is retired")
+	}
+
+	newToken, err := newKeys.MakeJWT(auth.AccessToken{UserID: userID}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oldKeys.ParseJWT(newToken); err == nil {
+		t.Error("expected token signed with unknown key to be rejected")
+	}
+}
+
+func TestKeyStoreRejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "main", private)
+	keys, err := auth.LoadKeyStore(dir, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An HS256 token keyed with the published public key must not verify.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	token.Header["kid"] = "main"
+	forged, err := token.SignedString([]byte(public))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.ParseJWT(forged); err == nil {
+		t.Fatal("expected HS256 token to be rejected for an EdDSA key")
+	}
+
+	// Without a legacy secret, tokens without a kid are rejected too.
+	legacyToken, err := auth.MakeJWT(uuid.New(), "legacy", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.ParseJWT(legacyToken); err == nil {
+		t.Error("expected token without kid to be rejected")
+	}
+}
+
+func TestKeyStoreJWKS(t *testing.T) {
+	dir := t.TempDir()
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "ed", edPrivate)
+	writePublicKey(t, dir, "rsa", &rsaKey.PublicKey)
+
+	keys, err := auth.LoadKeyStore(dir, "", "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := keys.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 published keys, got %+v", set.Keys)
+	}
+	ed, rs := set.Keys[0], set.Keys[1]
+	if ed.KeyID != "ed" || ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != "EdDSA" || len(ed.X) == 0 {
+		t.Errorf("unexpected Ed25519 JWK %+v", ed)
+	}
+	if x, err := base64.RawURLEncoding.DecodeString(ed.X); err != nil || !bytes.Equal(x, edPublic) {
+		t.Errorf("x does not encode the public key: %q", ed.X)
+	}
+	if rs.KeyID != "rsa" || rs.KeyType != "RSA" || rs.Algorithm != "RS256" || rs.E != "AQAB" || len(rs.N) == 0 {
+		t.Errorf("unexpected RSA JWK %+v", rs)
+	}
+
+	if got := auth.NewHMACKeyStore("secret").JWKS(); len(got.Keys) != 0 {
+		t.Errorf("HMAC keys must not be published, got %+v", got.Keys)
+	}
+}
+
+func TestLoadKeyStoreErrors(t *testing.T) {
+	if _, err := auth.LoadKeyStore(t.TempDir(), "", ""); err == nil {
+		t.Error("expected error for a directory without private keys")
+	}
+
+	dir := t.TempDir()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "main", private)
+	if _, err := auth.LoadKeyStore(dir, "missing", ""); err == nil {
+		t.Error("expected error for an unknown signing key")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not pem"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.LoadKeyStore(dir, "", ""); err == nil {
+		t.Error("expected error for a file without PEM data")
+	}
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alexedwards/argon2id"
+)
+
+// LockoutPolicy decides how long to refuse logins after a run of failed
+// attempts. The first FreeAttempts failures cost nothing; each one after that
+// doubles the wait from BaseDelay up to MaxDelay, and reaching Threshold
+// failures locks the key out for LockoutDuration. Failures older than Window
+// are forgotten.
+type LockoutPolicy struct {
+	FreeAttempts    int
+	Threshold       int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+// DefaultAccountLockout is applied to attempts against a single email address.
+func DefaultAccountLockout() LockoutPolicy {
+	return LockoutPolicy{
+		FreeAttempts:    3,
+		Threshold:       10,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Window:          24 * time.Hour,
+	}
+}
+
+// DefaultIPLockout is applied to attempts from a single client address, which
+// may be shared by many people, so it tolerates far more failures.
+func DefaultIPLockout() LockoutPolicy {
+	return LockoutPolicy{
+		FreeAttempts:    20,
+		Threshold:       100,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Window:          24 * time.Hour,
+	}
+}
+
+// Delay returns how long to refuse further attempts after the given number
+// of consecutive failures.
+func (p LockoutPolicy) Delay(failures int) time.Duration {
+	if p.Threshold > 0 && failures >= p.Threshold {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// Locked reports whether the given number of failures reaches the lockout
+// threshold.
+func (p LockoutPolicy) Locked(failures int) bool {
+	return p.Threshold > 0 && failures >= p.Threshold
+}
+
+var dummyHash = sync.OnceValues(func() (string, error) {
+	return argon2id.CreateHash("chirpy-dummy-password", argon2id.DefaultParams)
+})
+
+// CheckDummyPasswordHash does the same work as CheckPasswordHash against a
+// hash that no password matches, so that a login for an unknown account
+// takes as long as one with a wrong password.
+func CheckDummyPasswordHash(password string) error {
+	hash, err := dummyHash()
+	if err != nil {
+		return err
+	}
+	_, err = argon2id.ComparePasswordAndHash(password, hash)
+	return err
+}
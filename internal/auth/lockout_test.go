@@ -0,0 +1,71 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func TestLockoutPolicyDelay(t *testing.T) {
+	policy := auth.LockoutPolicy{
+		FreeAttempts:    3,
+		Threshold:       10,
+		BaseDelay:       time.Second,
+		MaxDelay:        10 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+	}
+
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{6, 4 * time.Second},
+		{7, 8 * time.Second},
+		{8, 10 * time.Second},
+		{9, 10 * time.Second},
+		{10, 15 * time.Minute},
+		{50, 15 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := policy.Delay(tc.failures); got != tc.want {
+			t.Errorf("Delay(%d) = %s, want %s", tc.failures, got, tc.want)
+		}
+	}
+	if policy.Locked(9) || !policy.Locked(10) {
+		t.Error("expected lockout to start at the threshold")
+	}
+}
+
+func TestLockoutPolicyDelayDoesNotOverflow(t *testing.T) {
+	policy := auth.LockoutPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+	if got := policy.Delay(1000); got != time.Minute {
+		t.Errorf("Delay(1000) = %s, want %s", got, time.Minute)
+	}
+	if policy.Locked(1000) {
+		t.Error("a policy without a threshold should never lock")
+	}
+}
+
+func TestPasswordHashing(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword returned error: %v", err)
+	}
+	if match, err := auth.CheckPasswordHash("correct horse", hash); err != nil || !match {
+		t.Errorf("expected match, got %v (err %v)", match, err)
+	}
+	if match, _ := auth.CheckPasswordHash("battery staple", hash); match {
+		t.Error("expected wrong password not to match")
+	}
+	if _, err := auth.CheckPasswordHash("correct horse", "not a hash"); err == nil {
+		t.Error("expected error for malformed hash")
+	}
+	if err := auth.CheckDummyPasswordHash("anything"); err != nil {
+		t.Errorf("CheckDummyPasswordHash returned error: %v", err)
+	}
+}
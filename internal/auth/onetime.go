@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidOneTimeToken = errors.New("invalid one-time token")
+
+// MakeOneTimeToken returns a random token signed with secret, for links sent
+// by email, together with the hash to store it under. Only the hash is kept,
+// so a leaked database can't be used to redeem outstanding tokens.
+func MakeOneTimeToken(secret string) (token, hash string) {
+	nonce := make([]byte, 32)
+	rand.Read(nonce)
+	payload := hex.EncodeToString(nonce)
+	token = payload + "." + signOneTimeToken(payload, secret)
+	return token, HashOneTimeToken(token)
+}
+
+// VerifyOneTimeToken checks the token's signature, which turns away forged
+// or mangled tokens before a database lookup, and returns the hash it was
+// stored under.
+func VerifyOneTimeToken(token, secret string) (string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signOneTimeToken(payload, secret))) {
+		return "", ErrInvalidOneTimeToken
+	}
+	return HashOneTimeToken(token), nil
+}
+
+func HashOneTimeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func signOneTimeToken(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,43 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func TestOneTimeToken(t *testing.T) {
+	token, hash := auth.MakeOneTimeToken("mailsecret")
+	other, _ := auth.MakeOneTimeToken("mailsecret")
+	if token == other {
+		t.Fatal("expected distinct tokens")
+	}
+
+	got, err := auth.VerifyOneTimeToken(token, "mailsecret")
+	if err != nil {
+		t.Fatalf("VerifyOneTimeToken returned error: %v", err)
+	}
+	if got != hash || got != auth.HashOneTimeToken(token) {
+		t.Errorf("hash mismatch: %q vs %q", got, hash)
+	}
+
+	tampered := "a" + token[1:]
+	if token[0] == 'a' {
+		tampered = "b" + token[1:]
+	}
+	for name, bad := range map[string]string{
+		"wrong secret":     token,
+		"tampered payload": tampered,
+		"no signature":     token[:64],
+		"empty":            "",
+	} {
+		secret := "mailsecret"
+		if name == "wrong secret" {
+			secret = "othersecret"
+		}
+		if _, err := auth.VerifyOneTimeToken(bad, secret); !errors.Is(err, auth.ErrInvalidOneTimeToken) {
+			t.Errorf("%s: expected ErrInvalidOneTimeToken, got %v", name, err)
+		}
+	}
+}
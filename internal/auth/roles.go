@@ -0,0 +1,34 @@
+package auth
+
+import "fmt"
+
+// Role is what a user is allowed to do beyond using their own account. Each
+// role can do everything the ones before it can.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// AtLeast reports whether r grants everything required does. Unknown roles,
+// including the empty role of tokens issued before roles existed, grant
+// nothing beyond a plain user.
+func (r Role) AtLeast(required Role) bool {
+	return max(roleRanks[r], roleRanks[RoleUser]) >= roleRanks[required]
+}
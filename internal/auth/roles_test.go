@@ -0,0 +1,43 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	cases := []struct {
+		role     auth.Role
+		required auth.Role
+		want     bool
+	}{
+		{auth.RoleUser, auth.RoleUser, true},
+		{auth.RoleUser, auth.RoleModerator, false},
+		{auth.RoleModerator, auth.RoleModerator, true},
+		{auth.RoleModerator, auth.RoleAdmin, false},
+		{auth.RoleAdmin, auth.RoleModerator, true},
+		{auth.RoleAdmin, auth.RoleAdmin, true},
+		{"", auth.RoleUser, true},
+		{"", auth.RoleModerator, false},
+		{"superuser", auth.RoleAdmin, false},
+	}
+	for _, tc := range cases {
+		if got := tc.role.AtLeast(tc.required); got != tc.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tc.role, tc.required, got, tc.want)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, name := range []string{"user", "moderator", "admin"} {
+		if role, err := auth.ParseRole(name); err != nil || string(role) != name {
+			t.Errorf("ParseRole(%q) = %q, %v", name, role, err)
+		}
+	}
+	for _, name := range []string{"", "Admin", "root"} {
+		if _, err := auth.ParseRole(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Errors returned when an access token is refused, so callers can tell the
+// client whether refreshing the token will help.
+var (
+	ErrNoBearerToken         = errors.New("no bearer token found")
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenNotYetValid      = errors.New("token is not valid yet")
+	ErrTokenInvalidClaims    = errors.New("token has invalid claims")
+)
+
+// ValidationOptions control which access tokens ParseJWT accepts. Issuer and
+// Audiences are also written into the tokens MakeJWT issues. An empty
+// Algorithms list allows the algorithm of every key in the store.
+// RequiredClaims may only name claims MakeJWT writes: sub, exp and iat, plus
+// iss and aud when Issuer and Audiences are set.
+type ValidationOptions struct {
+	Issuer         string
+	Audiences      []string
+	Algorithms     []string
+	Leeway         time.Duration
+	RequiredClaims []string
+}
+
+var knownAlgorithms = []string{"HS256", "RS256", "EdDSA"}
+
+var knownClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"}
+
+func DefaultValidationOptions() ValidationOptions {
+	return ValidationOptions{
+		Issuer:         "chirpy",
+		RequiredClaims: []string{"exp", "iat", "sub"},
+	}
+}
+
+// SetValidation replaces the key store's validation options. It refuses
+// options that would reject the tokens the store itself signs.
+func (ks *KeyStore) SetValidation(opts ValidationOptions) error {
+	for _, alg := range opts.Algorithms {
+		if !slices.Contains(knownAlgorithms, alg) {
+			return fmt.Errorf("unsupported algorithm %q", alg)
+		}
+	}
+	if len(opts.Algorithms) != 0 && !slices.Contains(opts.Algorithms, ks.signing.method.Alg()) {
+		return fmt.Errorf("signing algorithm %s is not in the allowed algorithms", ks.signing.method.Alg())
+	}
+	for _, claim := range opts.RequiredClaims {
+		if !slices.Contains(knownClaims, claim) {
+			return fmt.Errorf("unknown claim %q", claim)
+		}
+		if !slices.Contains(signedClaims(opts), claim) {
+			return fmt.Errorf("required claim %q is not in the tokens this server issues", claim)
+		}
+	}
+	if opts.Leeway < 0 {
+		return fmt.Errorf("leeway must not be negative")
+	}
+	ks.validation = opts
+	return nil
+}
+
+// signedClaims lists the registered claims MakeJWT puts in a token under
+// the given options.
+func signedClaims(opts ValidationOptions) []string {
+	claims := []string{"sub", "exp", "iat"}
+	if len(opts.Issuer) != 0 {
+		claims = append(claims, "iss")
+	}
+	if len(opts.Audiences) != 0 {
+		claims = append(claims, "aud")
+	}
+	return claims
+}
+
+func (ks *KeyStore) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{jwt.WithLeeway(ks.validation.Leeway), jwt.WithIssuedAt()}
+	if len(ks.validation.Algorithms) != 0 {
+		opts = append(opts, jwt.WithValidMethods(ks.validation.Algorithms))
+	}
+	if len(ks.validation.Issuer) != 0 {
+		opts = append(opts, jwt.WithIssuer(ks.validation.Issuer))
+	}
+	if len(ks.validation.Audiences) != 0 {
+		opts = append(opts, jwt.WithAudience(ks.validation.Audiences...))
+	}
+	if slices.Contains(ks.validation.RequiredClaims, "exp") {
+		opts = append(opts, jwt.WithExpirationRequired())
+	}
+	return opts
+}
+
+// checkRequiredClaims covers the required claims the parser has no option
+// for.
+func (ks *KeyStore) checkRequiredClaims(claims *accessClaims) error {
+	for _, claim := range ks.validation.RequiredClaims {
+		var present bool
+		switch claim {
+		case "iss":
+			present = len(claims.Issuer) != 0
+		case "sub":
+			present = len(claims.Subject) != 0
+		case "aud":
+			present = len(claims.Audience) != 0
+		case "exp":
+			present = claims.ExpiresAt != nil
+		case "nbf":
+			present = claims.NotBefore != nil
+		case "iat":
+			present = claims.IssuedAt != nil
+		case "jti":
+			present = len(claims.ID) != 0
+		}
+		if !present {
+			return fmt.Errorf("%w: missing %s", ErrTokenInvalidClaims, claim)
+		}
+	}
+	return nil
+}
+
+// classifyError maps the parser's errors onto the ones this package exports.
+func classifyError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotYetValid
+	}
+	return fmt.Errorf("%w: %w", ErrTokenInvalidClaims, err)
+}
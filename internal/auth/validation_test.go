@@ -0,0 +1,180 @@
+package auth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func signHS256(t *testing.T, secret string, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+func TestParseJWTErrors(t *testing.T) {
+	secret := "validationsecret"
+	keys := auth.NewHMACKeyStore(secret)
+	now := time.Now()
+	valid := jwt.RegisteredClaims{
+		Issuer:    "chirpy",
+		Subject:   uuid.NewString(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+	noIssuedAt := valid
+	noIssuedAt.IssuedAt = nil
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+	future := valid
+	future.NotBefore = jwt.NewNumericDate(now.Add(time.Hour))
+	otherIssuer := valid
+	otherIssuer.Issuer = "someone-else"
+	badSubject := valid
+	badSubject.Subject = "not-a-uuid"
+
+	cases := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", signHS256(t, secret, valid), nil},
+		{"malformed", "not.a.jwt", auth.ErrTokenMalformed},
+		{"wrong secret", signHS256(t, "other", valid), auth.ErrTokenSignatureInvalid},
+		{"expired", signHS256(t, secret, expired), auth.ErrTokenExpired},
+		{"not valid yet", signHS256(t, secret, future), auth.ErrTokenNotYetValid},
+		{"missing exp", signHS256(t, secret, noExpiry), auth.ErrTokenInvalidClaims},
+		{"missing iat", signHS256(t, secret, noIssuedAt), auth.ErrTokenInvalidClaims},
+		{"wrong issuer", signHS256(t, secret, otherIssuer), auth.ErrTokenInvalidClaims},
+		{"bad subject", signHS256(t, secret, badSubject), auth.ErrTokenInvalidClaims},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := keys.ParseJWT(tc.token)
+			if tc.want == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestParseJWTAudienceAndLeeway(t *testing.T) {
+	keys := auth.NewHMACKeyStore("audiencesecret")
+	opts := auth.DefaultValidationOptions()
+	opts.Audiences = []string{"chirpy-api", "chirpy-media"}
+	opts.Leeway = time.Minute
+	if err := keys.SetValidation(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenString, err := keys.MakeJWT(auth.AccessToken{UserID: uuid.New()}, -30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.ParseJWT(tokenString); err != nil {
+		t.Errorf("expected token within leeway with our audiences to be accepted: %v", err)
+	}
+
+	other := auth.NewHMACKeyStore("audiencesecret")
+	opts.Audiences = []string{"somewhere-else"}
+	if err := other.SetValidation(opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.ParseJWT(tokenString); !errors.Is(err, auth.ErrTokenInvalidClaims) {
+		t.Errorf("expected audience mismatch to be rejected, got %v", err)
+	}
+}
+
+func TestParseJWTAlgorithms(t *testing.T) {
+	dir := t.TempDir()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "main", private)
+	keys, err := auth.LoadKeyStore(dir, "", "legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacyToken, err := auth.MakeJWT(uuid.New(), "legacy", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.ParseJWT(legacyToken); err != nil {
+		t.Fatalf("expected HS256 token to be accepted by default: %v", err)
+	}
+
+	opts := auth.DefaultValidationOptions()
+	opts.Algorithms = []string{"EdDSA"}
+	if err := keys.SetValidation(opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.ParseJWT(legacyToken); !errors.Is(err, auth.ErrTokenSignatureInvalid) {
+		t.Errorf("expected HS256 token to be rejected, got %v", err)
+	}
+
+	opts.Algorithms = []string{"RS256"}
+	if err := keys.SetValidation(opts); err == nil {
+		t.Error("expected error when the signing algorithm is not allowed")
+	}
+	opts.Algorithms = []string{"none"}
+	if err := keys.SetValidation(opts); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+	opts = auth.DefaultValidationOptions()
+	opts.RequiredClaims = []string{"exp", "colour"}
+	if err := keys.SetValidation(opts); err == nil {
+		t.Error("expected error for unknown required claim")
+	}
+}
+
+func TestSetValidationRejectsClaimsNotIssued(t *testing.T) {
+	keys := auth.NewHMACKeyStore("secret")
+	for _, tc := range []struct {
+		name   string
+		modify func(*auth.ValidationOptions)
+	}{
+		{"jti", func(o *auth.ValidationOptions) { o.RequiredClaims = []string{"jti"} }},
+		{"nbf", func(o *auth.ValidationOptions) { o.RequiredClaims = []string{"exp", "nbf"} }},
+		{"iss without issuer", func(o *auth.ValidationOptions) { o.Issuer = ""; o.RequiredClaims = []string{"iss"} }},
+		{"aud without audiences", func(o *auth.ValidationOptions) { o.RequiredClaims = []string{"aud"} }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := auth.DefaultValidationOptions()
+			tc.modify(&opts)
+			if err := keys.SetValidation(opts); err == nil {
+				t.Error("expected SetValidation to refuse a claim its tokens don't carry")
+			}
+		})
+	}
+
+	opts := auth.DefaultValidationOptions()
+	opts.Audiences = []string{"chirpy-api"}
+	opts.RequiredClaims = []string{"iss", "aud", "sub", "exp", "iat"}
+	if err := keys.SetValidation(opts); err != nil {
+		t.Fatalf("expected every claim the server issues to be allowed, got %v", err)
+	}
+	token, err := keys.MakeJWT(auth.AccessToken{UserID: uuid.New()}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.ParseJWT(token); err != nil {
+		t.Errorf("expected the server's own token to satisfy the required claims, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/ttlcache"
+)
+
+// VersionCache remembers users' token versions for a short time so that
+// checking an access token doesn't need a database round trip on every
+// request. Versions set through this process are seen immediately; ones
+// bumped by another instance are picked up once the entry expires.
+type VersionCache struct {
+	ttl     time.Duration
+	entries *ttlcache.Cache[uuid.UUID, int32]
+}
+
+// VersionCacheOption configures a VersionCache.
+type VersionCacheOption func(*versionCacheConfig)
+
+type versionCacheConfig struct {
+	now func() time.Time
+}
+
+// WithVersionClock makes the cache expire entries by now instead of the
+// system clock.
+func WithVersionClock(now func() time.Time) VersionCacheOption {
+	return func(c *versionCacheConfig) { c.now = now }
+}
+
+func NewVersionCache(ttl time.Duration, opts ...VersionCacheOption) *VersionCache {
+	config := versionCacheConfig{now: time.Now}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &VersionCache{ttl: ttl, entries: ttlcache.New[uuid.UUID, int32](config.now)}
+}
+
+func (c *VersionCache) Get(userID uuid.UUID) (int32, bool) {
+	return c.entries.Get(userID)
+}
+
+// Set caches the user's token version. Versions only go up, so a lower one
+// than is already cached is ignored; that way a version read from the
+// database just before it was bumped can't replace the bumped one.
+func (c *VersionCache) Set(userID uuid.UUID, version int32) {
+	c.entries.Update(userID, func(now time.Time, current int32, found bool) (int32, time.Time) {
+		if found {
+			version = max(version, current)
+		}
+		return version, now.Add(c.ttl)
+	})
+}
+
+func (c *VersionCache) Delete(userID uuid.UUID) {
+	c.entries.Delete(userID)
+}
@@ -0,0 +1,58 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/auth"
+)
+
+func TestVersionCache(t *testing.T) {
+	now := time.Now()
+	cache := auth.NewVersionCache(time.Minute, auth.WithVersionClock(func() time.Time { return now }))
+	userID := uuid.New()
+
+	if _, ok := cache.Get(userID); ok {
+		t.Fatal("expected miss for unknown user")
+	}
+
+	cache.Set(userID, 3)
+	if version, ok := cache.Get(userID); !ok || version != 3 {
+		t.Fatalf("expected version 3, got %d (hit %v)", version, ok)
+	}
+
+	cache.Set(userID, 4)
+	if version, _ := cache.Get(userID); version != 4 {
+		t.Errorf("expected Set to overwrite, got %d", version)
+	}
+
+	cache.Delete(userID)
+	if _, ok := cache.Get(userID); ok {
+		t.Error("expected miss after Delete")
+	}
+
+	cache.Set(userID, 5)
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get(userID); ok {
+		t.Error("expected entry to expire after the TTL")
+	}
+}
+
+func TestVersionCacheKeepsHighestVersion(t *testing.T) {
+	now := time.Now()
+	cache := auth.NewVersionCache(time.Minute, auth.WithVersionClock(func() time.Time { return now }))
+	userID := uuid.New()
+
+	cache.Set(userID, 5)
+	cache.Set(userID, 4)
+	if version, _ := cache.Get(userID); version != 5 {
+		t.Errorf("expected a lower version not to replace a higher one, got %d", version)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Set(userID, 4)
+	if version, _ := cache.Get(userID); version != 4 {
+		t.Errorf("expected an expired version to be replaced, got %d", version)
+	}
+}
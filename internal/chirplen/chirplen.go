@@ -0,0 +1,145 @@
+// Package chirplen measures chirp bodies the way readers perceive them: in
+// grapheme clusters rather than bytes or code points, with links counted at
+// a fixed weight however long they are.
+package chirplen
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// URLWeight is the length every link in a chirp counts as.
+const URLWeight = 23
+
+var urlPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+
+// Count returns the length of text in grapheme clusters, with each URL
+// counted as URLWeight.
+func Count(text string) int {
+	length := 0
+	for {
+		loc := urlPattern.FindStringIndex(text)
+		if loc == nil {
+			return length + Graphemes(text)
+		}
+		// Trailing punctuation usually ends the sentence, not the link.
+		end := loc[0] + len(strings.TrimRight(text[loc[0]:loc[1]], ".,:;!?'\")]"))
+		length += Graphemes(text[:loc[0]]) + URLWeight
+		text = text[end:]
+	}
+}
+
+// Graphemes returns the number of user-perceived characters in s. It follows
+// the extended grapheme cluster rules of UAX #29 closely enough for chirps:
+// combining and spacing marks, variation selectors, emoji modifiers and tags
+// attach to the preceding character, ZWJ joins emoji sequences, regional
+// indicators pair into flags, CR LF and Hangul syllable sequences stay whole.
+func Graphemes(s string) int {
+	count := 0
+	prev := rune(-1)
+	riRun := 0
+	for len(s) != 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		if prev < 0 || breaksBetween(prev, r, riRun) {
+			count++
+		}
+		if isRegionalIndicator(r) {
+			riRun++
+		} else {
+			riRun = 0
+		}
+		prev = r
+	}
+	return count
+}
+
+// breaksBetween reports whether a cluster boundary falls between prev and r.
+// riRun is the number of consecutive regional indicators ending at prev.
+func breaksBetween(prev, r rune, riRun int) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return false
+	case isControl(prev) || isControl(r):
+		return true
+	case joinsHangul(hangulType(prev), hangulType(r)):
+		return false
+	case isExtend(r) || r == zwj || unicode.Is(unicode.Mc, r):
+		return false
+	case prev == zwj && isPictographic(r):
+		return false
+	case isRegionalIndicator(prev) && isRegionalIndicator(r):
+		return riRun%2 == 0
+	}
+	return true
+}
+
+const zwj = '\u200d'
+
+func isControl(r rune) bool {
+	return r == '\r' || r == '\n' || (unicode.IsControl(r) && r != zwj) ||
+		r == '\u2028' || r == '\u2029'
+}
+
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) ||
+		r == '\u200c' ||
+		(r >= 0xFE00 && r <= 0xFE0F) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) ||
+		(r >= 0xE0020 && r <= 0xE007F) ||
+		(r >= 0xE0100 && r <= 0xE01EF)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+func isPictographic(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) ||
+		(r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2300 && r <= 0x23FF) ||
+		(r >= 0x2B00 && r <= 0x2BFF) ||
+		r == 0x00A9 || r == 0x00AE || r == 0x203C || r == 0x2049 || r == 0x2122
+}
+
+type hangul int
+
+const (
+	hangulNone hangul = iota
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+)
+
+func hangulType(r rune) hangul {
+	switch {
+	case (r >= 0x1100 && r <= 0x115F) || (r >= 0xA960 && r <= 0xA97C):
+		return hangulL
+	case (r >= 0x1160 && r <= 0x11A7) || (r >= 0xD7B0 && r <= 0xD7C6):
+		return hangulV
+	case (r >= 0x11A8 && r <= 0x11FF) || (r >= 0xD7CB && r <= 0xD7FB):
+		return hangulT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	}
+	return hangulNone
+}
+
+func joinsHangul(prev, next hangul) bool {
+	switch prev {
+	case hangulL:
+		return next == hangulL || next == hangulV || next == hangulLV || next == hangulLVT
+	case hangulLV, hangulV:
+		return next == hangulV || next == hangulT
+	case hangulLVT, hangulT:
+		return next == hangulT
+	}
+	return false
+}
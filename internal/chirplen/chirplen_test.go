@@ -0,0 +1,68 @@
+package chirplen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/louiehdev/chirpy/internal/chirplen"
+)
+
+func TestGraphemes(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"empty", "", 0},
+		{"ascii", "hello", 5},
+		{"accented precomposed", "café", 4},
+		{"combining accent", "cafe\u0301", 4},
+		{"emoji", "😀😀😀", 3},
+		{"skin tone modifier", "👍🏽", 1},
+		{"zwj family", "👨‍👩‍👧‍👦", 1},
+		{"variation selector", "❤️", 1},
+		{"flags", "🇯🇵🇺🇸", 2},
+		{"odd regional indicators", "🇯🇵🇺", 2},
+		{"tag sequence", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", 1},
+		{"crlf", "a\r\nb", 3},
+		{"hangul syllables", "한국어", 3},
+		{"hangul jamo", "\u1112\u1161\u11ab", 1},
+		{"devanagari marks", "नमस्ते", 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := chirplen.Graphemes(tc.in); got != tc.want {
+				t.Errorf("Graphemes(%q) = %d, want %d", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCountWeightsURLs(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("a", 200)
+	cases := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"no url", "just text", 9},
+		{"url only", long, chirplen.URLWeight},
+		{"url in text", "see " + long + " now", 4 + chirplen.URLWeight + 4},
+		{"trailing punctuation", "read http://x.co.", chirplen.URLWeight + 6},
+		{"two urls", "http://a.io https://b.io", 2*chirplen.URLWeight + 1},
+		{"not a url", "httpx://nope", 12},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := chirplen.Count(tc.in); got != tc.want {
+				t.Errorf("Count(%q) = %d, want %d", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCountFiftyEmoji(t *testing.T) {
+	if got := chirplen.Count(strings.Repeat("🎉", 50)); got != 50 {
+		t.Errorf("Count(50 emoji) = %d, want 50", got)
+	}
+}
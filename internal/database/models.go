@@ -6,33 +6,203 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Name       string       `json:"name"`
+	TokenHash  string       `json:"token_hash"`
+	Scopes     []string     `json:"scopes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+}
+
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Body      string        `json:"body"`
+	UserID    uuid.UUID     `json:"user_id"`
+	InReplyTo uuid.NullUUID `json:"in_reply_to"`
+	DeletedAt sql.NullTime  `json:"deleted_at"`
+	RechirpOf uuid.NullUUID `json:"rechirp_of"`
+	QuoteOf   uuid.NullUUID `json:"quote_of"`
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Tag       string    `json:"tag"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
 	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-type RefreshToken struct {
-	Token     string       `json:"token"`
+type ChirpMention struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Handle    string    `json:"handle"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID `json:"id"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+type ChirpSearch struct {
+	ChirpID  uuid.UUID   `json:"chirp_id"`
+	Document interface{} `json:"document"`
+}
+
+type EmailVerificationToken struct {
+	TokenHash string       `json:"token_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Email     string       `json:"email"`
 	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at"`
 	ExpiresAt time.Time    `json:"expires_at"`
-	RevokedAt sql.NullTime `json:"revoked_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type LoginFailure struct {
+	Key          string    `json:"key"`
+	Failures     int32     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	BlockedUntil time.Time `json:"blocked_until"`
+}
+
+type MfaChallenge struct {
+	TokenHash  string       `json:"token_hash"`
+	UserID     uuid.UUID    `json:"user_id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	Attempts   int32        `json:"attempts"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+}
+
+type MfaRecoveryCode struct {
+	CodeHash  string       `json:"code_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type ModerationFlag struct {
+	ID         uuid.UUID    `json:"id"`
+	ChirpID    uuid.UUID    `json:"chirp_id"`
+	Terms      []string     `json:"terms"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ResolvedAt sql.NullTime `json:"resolved_at"`
+}
+
+type ModerationTerm struct {
+	Term      string    `json:"term"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PasswordResetToken struct {
+	TokenHash string       `json:"token_hash"`
 	UserID    uuid.UUID    `json:"user_id"`
+	Email     string       `json:"email"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type RefreshToken struct {
+	Token      string         `json:"token"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	ExpiresAt  time.Time      `json:"expires_at"`
+	RevokedAt  sql.NullTime   `json:"revoked_at"`
+	UserID     uuid.UUID      `json:"user_id"`
+	FamilyID   uuid.UUID      `json:"family_id"`
+	ReplacedBy sql.NullString `json:"replaced_by"`
+	UserAgent  string         `json:"user_agent"`
+	IpAddress  string         `json:"ip_address"`
+	LastUsedAt time.Time      `json:"last_used_at"`
+}
+
+type SecurityEvent struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	EventType string    `json:"event_type"`
+	IpAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Subscription struct {
+	UserID             uuid.UUID    `json:"user_id"`
+	Plan               string       `json:"plan"`
+	Status             string       `json:"status"`
+	CurrentPeriodStart time.Time    `json:"current_period_start"`
+	CurrentPeriodEnd   sql.NullTime `json:"current_period_end"`
+	GraceUntil         sql.NullTime `json:"grace_until"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
+}
+
+type SubscriptionEvent struct {
+	ID         uuid.UUID      `json:"id"`
+	UserID     uuid.UUID      `json:"user_id"`
+	Event      string         `json:"event"`
+	Status     string         `json:"status"`
+	DeliveryID sql.NullString `json:"delivery_id"`
+	CreatedAt  time.Time      `json:"created_at"`
 }
 
 type User struct {
-	ID             uuid.UUID `json:"id"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	Email          string    `json:"email"`
-	HashedPassword string    `json:"hashed_password"`
-	IsChirpyRed    bool      `json:"is_chirpy_red"`
+	ID              uuid.UUID      `json:"id"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	Email           string         `json:"email"`
+	HashedPassword  string         `json:"hashed_password"`
+	IsChirpyRed     bool           `json:"is_chirpy_red"`
+	TokenVersion    int32          `json:"token_version"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+	TotpSecret      sql.NullString `json:"totp_secret"`
+	TotpEnabledAt   sql.NullTime   `json:"totp_enabled_at"`
+	TotpLastStep    int64          `json:"totp_last_step"`
+	Role            string         `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID       `json:"id"`
+	Source     string          `json:"source"`
+	DeliveryID string          `json:"delivery_id"`
+	Event      string          `json:"event"`
+	Headers    json.RawMessage `json:"headers"`
+	Body       []byte          `json:"body"`
+	ReceivedAt time.Time       `json:"received_at"`
+	StatusCode int32           `json:"status_code"`
+	Outcome    string          `json:"outcome"`
+	Error      string          `json:"error"`
+	ReplayOf   uuid.NullUUID   `json:"replay_of"`
+}
+
+type WebhookEvent struct {
+	DeliveryID string    `json:"delivery_id"`
+	Event      string    `json:"event"`
+	ReceivedAt time.Time `json:"received_at"`
 }
@@ -7,11 +7,26 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const bumpUserTokenVersion = `-- name: BumpUserTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING token_version
+`
+
+func (q *Queries) BumpUserTokenVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, bumpUserTokenVersion, id)
+	var tokenVersion int32
+	err := row.Scan(&tokenVersion)
+	return tokenVersion, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, created_at, updated_at, email, hashed_password)
 VALUES (
@@ -50,6 +65,19 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteUsers = `-- name: DeleteUsers :exec
 DELETE FROM users
 `
@@ -60,7 +88,7 @@ func (q *Queries) DeleteUsers(ctx context.Context) error {
 }
 
 const getUserFromEmail = `-- name: GetUserFromEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, token_version, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, role FROM users
 WHERE email = $1
 `
 
@@ -74,13 +102,207 @@ func (q *Queries) GetUserFromEmail(ctx context.Context, email string) (User, err
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserFromID = `-- name: GetUserFromID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, token_version, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, role FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserFromID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserFromID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.Role,
 	)
 	return i, err
 }
 
+const getUserTokenVersion = `-- name: GetUserTokenVersion :one
+SELECT token_version FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserTokenVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getUserTokenVersion, id)
+	var tokenVersion int32
+	err := row.Scan(&tokenVersion)
+	return tokenVersion, err
+}
+
+const listUsersAfter = `-- name: ListUsersAfter :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, token_version, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, role FROM users
+WHERE ($1::timestamp IS NULL OR (created_at, id) > ($1, $2::uuid))
+AND ($3::text IS NULL OR role = $3)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListUsersAfterParams struct {
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        uuid.NullUUID  `json:"cursor_id"`
+	Role            sql.NullString `json:"role"`
+	RowLimit        int32          `json:"row_limit"`
+}
+
+func (q *Queries) ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersAfter,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Role,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.TokenVersion,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersBefore = `-- name: ListUsersBefore :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, token_version, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, role FROM users
+WHERE ($1::timestamp IS NULL OR (created_at, id) < ($1, $2::uuid))
+AND ($3::text IS NULL OR role = $3)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListUsersBeforeParams struct {
+	CursorCreatedAt sql.NullTime   `json:"cursor_created_at"`
+	CursorID        uuid.NullUUID  `json:"cursor_id"`
+	Role            sql.NullString `json:"role"`
+	RowLimit        int32          `json:"row_limit"`
+}
+
+func (q *Queries) ListUsersBefore(ctx context.Context, arg ListUsersBeforeParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersBefore,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Role,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.TokenVersion,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserChirpyRed = `-- name: SetUserChirpyRed :execrows
+UPDATE users
+SET is_chirpy_red = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserChirpyRedParams struct {
+	ID          uuid.UUID `json:"id"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+}
+
+func (q *Queries) SetUserChirpyRed(ctx context.Context, arg SetUserChirpyRedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserChirpyRed, arg.ID, arg.IsChirpyRed)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $2, token_version = token_version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING token_version
+`
+
+type SetUserRoleParams struct {
+	ID   uuid.UUID `json:"id"`
+	Role string    `json:"role"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.ID, arg.Role)
+	var tokenVersion int32
+	err := row.Scan(&tokenVersion)
+	return tokenVersion, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET email = $1, hashed_password = $2
+SET email = $1, hashed_password = $2, updated_at = NOW(),
+    email_verified_at = CASE WHEN email = $1 THEN email_verified_at END
 WHERE id = $3
 RETURNING id, created_at, updated_at, email, is_chirpy_red
 `
@@ -111,14 +333,3 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 	)
 	return i, err
 }
-
-const upgradeUser = `-- name: UpgradeUser :exec
-UPDATE users
-SET is_chirpy_red = TRUE
-WHERE id = $1
-`
-
-func (q *Queries) UpgradeUser(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, upgradeUser, id)
-	return err
-}
@@ -0,0 +1,160 @@
+// Package entitlements maps subscription plans to what users on them may do,
+// such as how long their chirps can be and how long they can edit them for.
+package entitlements
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entitlements are the capabilities of one plan.
+type Entitlements struct {
+	Plan             string   `json:"plan"`
+	MaxChirpLength   int      `json:"max_chirp_length"`
+	EditWindow       Duration `json:"edit_window"`
+	MediaAttachments int      `json:"media_attachments"`
+	ScheduledChirps  int      `json:"scheduled_chirps"`
+	APIRateLimit     int      `json:"api_rate_limit"`
+}
+
+// Duration is a time.Duration written in JSON as a string such as "15m".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"15m\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Catalog holds the entitlements of every plan. Users without a
+// subscription, or on a plan the catalog doesn't know, get the default plan.
+type Catalog struct {
+	defaultPlan string
+	plans       map[string]Entitlements
+}
+
+// DefaultCatalog is used when no plan file is configured.
+func DefaultCatalog() *Catalog {
+	return &Catalog{
+		defaultPlan: "free",
+		plans: map[string]Entitlements{
+			"free": {
+				Plan:           "free",
+				MaxChirpLength: 140,
+				EditWindow:     Duration(15 * time.Minute),
+				APIRateLimit:   60,
+			},
+			"red": {
+				Plan:             "red",
+				MaxChirpLength:   280,
+				EditWindow:       Duration(time.Hour),
+				MediaAttachments: 4,
+				ScheduledChirps:  20,
+				APIRateLimit:     600,
+			},
+		},
+	}
+}
+
+// LoadCatalog reads a plan file: a JSON object naming the default_plan and
+// giving the entitlements of each entry in plans.
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := ParseCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return catalog, nil
+}
+
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var file struct {
+		DefaultPlan string                  `json:"default_plan"`
+		Plans       map[string]Entitlements `json:"plans"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if _, ok := file.Plans[file.DefaultPlan]; !ok {
+		return nil, fmt.Errorf("default plan %q is not defined", file.DefaultPlan)
+	}
+	for name, plan := range file.Plans {
+		plan.Plan = name
+		if err := plan.validate(); err != nil {
+			return nil, err
+		}
+		file.Plans[name] = plan
+	}
+	return &Catalog{defaultPlan: file.DefaultPlan, plans: file.Plans}, nil
+}
+
+func (e Entitlements) validate() error {
+	if e.MaxChirpLength <= 0 {
+		return fmt.Errorf("plan %q: max_chirp_length must be positive", e.Plan)
+	}
+	if e.EditWindow < 0 || e.MediaAttachments < 0 || e.ScheduledChirps < 0 || e.APIRateLimit < 0 {
+		return fmt.Errorf("plan %q: limits must not be negative", e.Plan)
+	}
+	return nil
+}
+
+// SetPlan adds a plan to the catalog, or replaces the one with its name.
+func (c *Catalog) SetPlan(plan Entitlements) error {
+	if err := plan.validate(); err != nil {
+		return err
+	}
+	c.plans[plan.Plan] = plan
+	return nil
+}
+
+// Plan returns the entitlements of the named plan, falling back to the
+// default plan.
+func (c *Catalog) Plan(name string) Entitlements {
+	if plan, ok := c.plans[name]; ok {
+		return plan
+	}
+	return c.plans[c.defaultPlan]
+}
+
+// PlanStore reports which plan a user is subscribed to, or "" if none.
+type PlanStore interface {
+	GetUserPlan(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// Resolver answers what a user may do, looking up their plan in a PlanStore
+// and its entitlements in a Catalog.
+type Resolver struct {
+	catalog *Catalog
+	store   PlanStore
+}
+
+func NewResolver(catalog *Catalog, store PlanStore) *Resolver {
+	return &Resolver{catalog: catalog, store: store}
+}
+
+func (r *Resolver) ForUser(ctx context.Context, userID uuid.UUID) (Entitlements, error) {
+	plan, err := r.store.GetUserPlan(ctx, userID)
+	if err != nil {
+		return Entitlements{}, err
+	}
+	return r.catalog.Plan(plan), nil
+}
@@ -0,0 +1,133 @@
+package entitlements_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/entitlements"
+)
+
+const planFile = `{
+	"default_plan": "free",
+	"plans": {
+		"free": {"max_chirp_length": 100, "edit_window": "5m", "api_rate_limit": 30},
+		"red": {"max_chirp_length": 500, "edit_window": "2h", "media_attachments": 4, "scheduled_chirps": 10, "api_rate_limit": 300}
+	}
+}`
+
+func TestLoadCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plans.json")
+	if err := os.WriteFile(path, []byte(planFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := entitlements.LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog returned error: %v", err)
+	}
+
+	want := entitlements.Entitlements{
+		Plan:             "red",
+		MaxChirpLength:   500,
+		EditWindow:       entitlements.Duration(2 * time.Hour),
+		MediaAttachments: 4,
+		ScheduledChirps:  10,
+		APIRateLimit:     300,
+	}
+	if got := catalog.Plan("red"); got != want {
+		t.Errorf("Plan(red) = %+v, want %+v", got, want)
+	}
+	for _, name := range []string{"", "platinum"} {
+		if got := catalog.Plan(name); got.Plan != "free" || got.MaxChirpLength != 100 {
+			t.Errorf("Plan(%q) = %+v, want the free plan", name, got)
+		}
+	}
+}
+
+func TestParseCatalogRejectsInvalidFiles(t *testing.T) {
+	for name, data := range map[string]string{
+		"not json":          `plans`,
+		"missing default":   `{"default_plan": "basic", "plans": {"free": {"max_chirp_length": 140}}}`,
+		"no length":         `{"default_plan": "free", "plans": {"free": {"edit_window": "1m"}}}`,
+		"negative limit":    `{"default_plan": "free", "plans": {"free": {"max_chirp_length": 140, "api_rate_limit": -1}}}`,
+		"bad duration":      `{"default_plan": "free", "plans": {"free": {"max_chirp_length": 140, "edit_window": "soon"}}}`,
+		"numeric duration":  `{"default_plan": "free", "plans": {"free": {"max_chirp_length": 140, "edit_window": 60}}}`,
+		"negative duration": `{"default_plan": "free", "plans": {"free": {"max_chirp_length": 140, "edit_window": "-1m"}}}`,
+	} {
+		if _, err := entitlements.ParseCatalog([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	catalog := entitlements.DefaultCatalog()
+	free, red := catalog.Plan(""), catalog.Plan("red")
+	if free.Plan != "free" || free.MaxChirpLength != 140 || time.Duration(free.EditWindow) != 15*time.Minute {
+		t.Errorf("unexpected free plan %+v", free)
+	}
+	if red.MaxChirpLength != 280 || time.Duration(red.EditWindow) != time.Hour {
+		t.Errorf("unexpected red plan %+v", red)
+	}
+}
+
+func TestSetPlan(t *testing.T) {
+	catalog := entitlements.DefaultCatalog()
+	free := catalog.Plan("free")
+	free.MaxChirpLength = 200
+	if err := catalog.SetPlan(free); err != nil {
+		t.Fatalf("SetPlan returned error: %v", err)
+	}
+	if got := catalog.Plan(""); got.MaxChirpLength != 200 {
+		t.Errorf("expected the default plan to be replaced, got %+v", got)
+	}
+
+	free.MaxChirpLength = 0
+	if err := catalog.SetPlan(free); err == nil {
+		t.Error("expected an invalid plan to be refused")
+	}
+	if got := catalog.Plan("free"); got.MaxChirpLength != 200 {
+		t.Errorf("expected a refused plan to leave the catalog alone, got %+v", got)
+	}
+}
+
+func TestEntitlementsJSON(t *testing.T) {
+	data, err := json.Marshal(entitlements.DefaultCatalog().Plan("free"))
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	want := `{"plan":"free","max_chirp_length":140,"edit_window":"15m0s","media_attachments":0,"scheduled_chirps":0,"api_rate_limit":60}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+}
+
+type planStore map[uuid.UUID]string
+
+func (s planStore) GetUserPlan(_ context.Context, userID uuid.UUID) (string, error) {
+	plan, ok := s[userID]
+	if !ok {
+		return "", errors.New("no such user")
+	}
+	return plan, nil
+}
+
+func TestResolverForUser(t *testing.T) {
+	subscriber, freeloader, stranger := uuid.New(), uuid.New(), uuid.New()
+	resolver := entitlements.NewResolver(entitlements.DefaultCatalog(), planStore{subscriber: "red", freeloader: ""})
+
+	if got, err := resolver.ForUser(context.Background(), subscriber); err != nil || got.Plan != "red" {
+		t.Errorf("ForUser(subscriber) = %+v, %v", got, err)
+	}
+	if got, err := resolver.ForUser(context.Background(), freeloader); err != nil || got.Plan != "free" {
+		t.Errorf("ForUser(freeloader) = %+v, %v", got, err)
+	}
+	if _, err := resolver.ForUser(context.Background(), stranger); err == nil {
+		t.Error("expected the store's error to be returned")
+	}
+}
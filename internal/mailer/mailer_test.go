@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAddress(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"walt@breakingbad.com", "walt@breakingbad.com", true},
+		{"  saul@bcs.example.org ", "saul@bcs.example.org", true},
+		{"first.last+tag@sub.example.co.uk", "first.last+tag@sub.example.co.uk", true},
+		{"", "", false},
+		{"not-an-email", "", false},
+		{"walt@localhost", "", false},
+		{"walt@example.", "", false},
+		{"Walter White <walt@breakingbad.com>", "", false},
+		{"walt@breakingbad.com, jesse@breakingbad.com", "", false},
+		{"walt @breakingbad.com", "", false},
+	}
+	for _, tc := range cases {
+		got, err := ValidateAddress(tc.in)
+		if tc.ok && (err != nil || got != tc.want) {
+			t.Errorf("ValidateAddress(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("ValidateAddress(%q) = %q, expected an error", tc.in, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	msg := Message{To: "walt@breakingbad.com", Subject: "Vérifiez", Body: "line one\nline two"}
+	out := string(format("chirpy@example.com", msg, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	header, body, ok := strings.Cut(out, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no header/body separator in %q", out)
+	}
+	for _, want := range []string{
+		"From: chirpy@example.com",
+		"To: walt@breakingbad.com",
+		"Subject: =?utf-8?q?V=C3=A9rifiez?=",
+		"Date: Thu, 02 Jan 2025 03:04:05 +0000",
+		"Content-Type: text/plain; charset=utf-8",
+	} {
+		if !strings.Contains(header, want+"\r\n") {
+			t.Errorf("header missing %q:\n%s", want, header)
+		}
+	}
+	if body != "line one\r\nline two" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "chirpy@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := m.Send(context.Background(), Message{To: "walt@breakingbad.com", Subject: "hi", Body: "token"}); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected 2 messages, got %v (%v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("To: walt@breakingbad.com\r\n")) {
+		t.Errorf("unexpected message:\n%s", data)
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewLogMailer(&buf, "chirpy@example.com")
+	if err := m.Send(context.Background(), Message{To: "walt@breakingbad.com", Subject: "hi", Body: "your token"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "To: walt@breakingbad.com") || !strings.Contains(out, "your token") {
+		t.Errorf("unexpected log output:\n%s", out)
+	}
+}
+
+func TestSMTPMailerGivesUpWhenContextEnds(t *testing.T) {
+	// A server that accepts connections and never greets the client.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	m := NewSMTPMailer(host, port, "", "", "chirpy@example.com")
+	msg := Message{To: "walt@breakingbad.com", Subject: "hi", Body: "token"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Send(ctx, msg); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to end the send, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := m.Send(ctx, msg); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancelling to end the send, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package moderation
+
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'+': 't',
+}
+
+// confusables maps accented Latin letters and the Cyrillic and Greek letters
+// most often substituted for Latin ones to the plain letter they resemble.
+var confusables = map[rune]rune{}
+
+func init() {
+	for base, variants := range map[rune]string{
+		'a': "àáâãäåāăąǎȁȃȧаαΑА",
+		'b': "ƀɓвВβΒ",
+		'c': "çćĉċčсС",
+		'd': "ďđɗԁ",
+		'e': "èéêëēĕėęěȅȇеЕεΕё",
+		'g': "ĝğġģǧ",
+		'h': "ĥħһНΗ",
+		'i': "ìíîïĩīĭįıǐȉȋіІιΙ",
+		'j': "ĵјЈ",
+		'k': "ķǩкКκΚ",
+		'l': "ĺļľŀłӏ",
+		'm': "мМΜ",
+		'n': "ñńņňŉηΝ",
+		'o': "òóôõöøōŏőǒȍȏоОοΟσ",
+		'p': "рРρΡ",
+		'r': "ŕŗřȑȓг",
+		's': "śŝşšșѕЅ",
+		't': "ţťŧțтТτΤ",
+		'u': "ùúûüũūŭůűųǔȕȗυ",
+		'w': "ŵѡωԝ",
+		'x': "хХχΧ",
+		'y': "ýÿŷуУγΥ",
+		'z': "źżžΖ",
+	} {
+		for _, r := range variants {
+			confusables[r] = base
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionReject Action = "reject"
+	ActionFlag   Action = "flag"
+)
+
+const mask = "****"
+
+type Term struct {
+	Term   string `json:"term"`
+	Action Action `json:"action"`
+}
+
+// Result describes what the filter did to a piece of text. Body has every
+// masked term replaced; Rejected and Flagged report terms whose action asks
+// the caller to refuse the text or queue it for review.
+type Result struct {
+	Body     string
+	Rejected bool
+	Flagged  []string
+}
+
+// Filter matches words against a term list after normalizing both, so that
+// case, surrounding punctuation, leetspeak and look-alike characters don't
+// let a term slip through. It is safe for concurrent use.
+type Filter struct {
+	mu    sync.RWMutex
+	terms map[string]Action
+}
+
+func NewFilter(terms []Term) *Filter {
+	f := &Filter{}
+	f.SetTerms(terms)
+	return f
+}
+
+// SetTerms replaces the filter's term list. Later entries for the same
+// normalized term win.
+func (f *Filter) SetTerms(terms []Term) {
+	normalized := make(map[string]Action, len(terms))
+	for _, t := range terms {
+		if key := Normalize(t.Term); len(key) != 0 {
+			normalized[key] = t.Action
+		}
+	}
+	f.mu.Lock()
+	f.terms = normalized
+	f.mu.Unlock()
+}
+
+// Terms returns the normalized term list in alphabetical order.
+func (f *Filter) Terms() []Term {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	terms := make([]Term, 0, len(f.terms))
+	for term, action := range f.terms {
+		terms = append(terms, Term{Term: term, Action: action})
+	}
+	sort.Slice(terms, func(a, b int) bool { return terms[a].Term < terms[b].Term })
+	return terms
+}
+
+// Check runs text through the filter. Whitespace between words is kept
+// exactly as it was.
+func (f *Filter) Check(text string) Result {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var result Result
+	var body strings.Builder
+	body.Grow(len(text))
+
+	rest := text
+	for len(rest) != 0 {
+		space := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsSpace(r) })
+		if space == -1 {
+			body.WriteString(rest)
+			break
+		}
+		body.WriteString(rest[:space])
+		rest = rest[space:]
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end == -1 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+
+		term, action, start, stop := f.match(word)
+		switch {
+		case len(term) == 0:
+			body.WriteString(word)
+		case action == ActionMask:
+			body.WriteString(word[:start] + mask + word[stop:])
+		case action == ActionReject:
+			result.Rejected = true
+			body.WriteString(word)
+		default:
+			result.Flagged = append(result.Flagged, term)
+			body.WriteString(word)
+		}
+	}
+
+	result.Body = body.String()
+	return result
+}
+
+// match looks a single word up in the term list, first with its leading and
+// trailing punctuation removed and then as a whole, since some punctuation
+// doubles as leetspeak. It returns the matched term and the byte range of
+// word it covers.
+func (f *Filter) match(word string) (string, Action, int, int) {
+	trimmed := strings.TrimLeftFunc(word, isPunctuation)
+	start := len(word) - len(trimmed)
+	trimmed = strings.TrimRightFunc(trimmed, isPunctuation)
+	if term := Normalize(trimmed); len(term) != 0 {
+		if action, ok := f.terms[term]; ok {
+			return term, action, start, start + len(trimmed)
+		}
+	}
+	if term := Normalize(word); len(term) != 0 {
+		if action, ok := f.terms[term]; ok {
+			return term, action, 0, len(word)
+		}
+	}
+	return "", "", 0, 0
+}
+
+func isPunctuation(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// Normalize folds a word to the form terms are compared in: lower case, with
+// look-alike letters and leetspeak mapped to plain Latin letters and
+// everything else that isn't a letter dropped.
+func Normalize(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		r = fold(r)
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func fold(r rune) rune {
+	// Fullwidth forms are offset copies of printable ASCII.
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r -= 0xFEE0
+	}
+	// Capitals are looked up as they are first, since some look like a
+	// different letter than their lower case does, such as Greek Eta.
+	if mapped, ok := confusables[r]; ok {
+		return mapped
+	}
+	r = unicode.ToLower(r)
+	if mapped, ok := confusables[r]; ok {
+		return mapped
+	}
+	if mapped, ok := leetspeak[r]; ok {
+		return mapped
+	}
+	return r
+}
+
+// ParseTerm normalizes a term for the term list. Check matches one word at
+// a time, so terms of more than one word are refused rather than stored
+// where they could never match.
+func ParseTerm(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.IndexFunc(s, unicode.IsSpace) != -1 {
+		return "", fmt.Errorf("moderation term %q must be a single word", s)
+	}
+	term := Normalize(s)
+	if len(term) == 0 {
+		return "", fmt.Errorf("moderation term %q must contain letters", s)
+	}
+	return term, nil
+}
+
+func ParseAction(s string) (Action, error) {
+	switch Action(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ActionMask:
+		return ActionMask, nil
+	case ActionReject:
+		return ActionReject, nil
+	case ActionFlag:
+		return ActionFlag, nil
+	}
+	return "", fmt.Errorf("unknown moderation action %q", s)
+}
+
+// LoadTerms reads a term list from a file with one term per line, optionally
+// followed by a comma and its action. Blank lines and lines starting with #
+// are ignored; terms without an action are masked.
+func LoadTerms(path string) ([]Term, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var terms []Term
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		term, actionName, _ := strings.Cut(text, ",")
+		if _, err := ParseTerm(term); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		action, err := ParseAction(actionName)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		terms = append(terms, Term{Term: strings.TrimSpace(term), Action: action})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
@@ -0,0 +1,126 @@
+package moderation_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/louiehdev/chirpy/internal/moderation"
+)
+
+func TestCheckMasksTerms(t *testing.T) {
+	filter := moderation.NewFilter([]moderation.Term{
+		{Term: "kerfuffle", Action: moderation.ActionMask},
+		{Term: "sharbert", Action: moderation.ActionMask},
+		{Term: "fornax", Action: moderation.ActionMask},
+	})
+
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "I had a kerfuffle today", "I had a **** today"},
+		{"case", "KerFuffle happens", "**** happens"},
+		{"accented capitals", "KÉRFUFFLE happens", "**** happens"},
+		{"greek capitals", "FΟRΝΑΧ", "****"},
+		{"trailing punctuation", "what a kerfuffle!", "what a ****!"},
+		{"wrapped in punctuation", "(sharbert), right?", "(****), right?"},
+		{"leetspeak", "k3rfuffl3 and f0rn4x", "**** and ****"},
+		{"leetspeak symbol", "$harbert", "****"},
+		{"cyrillic look-alikes", "fоrnаx", "****"},
+		{"fullwidth", "ｋｅｒｆｕｆｆｌｅ", "****"},
+		{"zero width space", "kerf​uffle", "****"},
+		{"whitespace preserved", "  a\tkerfuffle \n b  ", "  a\t**** \n b  "},
+		{"substring untouched", "fornaxes are fine", "fornaxes are fine"},
+		{"empty", "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := filter.Check(tc.in)
+			if result.Body != tc.want {
+				t.Errorf("Check(%q).Body = %q, want %q", tc.in, result.Body, tc.want)
+			}
+			if result.Rejected || len(result.Flagged) != 0 {
+				t.Errorf("Check(%q) should only mask, got %+v", tc.in, result)
+			}
+		})
+	}
+}
+
+func TestCheckActions(t *testing.T) {
+	filter := moderation.NewFilter([]moderation.Term{
+		{Term: "spam", Action: moderation.ActionReject},
+		{Term: "scam", Action: moderation.ActionFlag},
+	})
+
+	result := filter.Check("not a scam")
+	if result.Rejected || !reflect.DeepEqual(result.Flagged, []string{"scam"}) || result.Body != "not a scam" {
+		t.Errorf("flag: got %+v", result)
+	}
+
+	result = filter.Check("buy SP4M now")
+	if !result.Rejected || result.Body != "buy SP4M now" {
+		t.Errorf("reject: got %+v", result)
+	}
+}
+
+func TestSetTermsReplacesList(t *testing.T) {
+	filter := moderation.NewFilter([]moderation.Term{{Term: "fornax", Action: moderation.ActionMask}})
+	filter.SetTerms([]moderation.Term{{Term: "Sharbert", Action: moderation.ActionFlag}})
+
+	if got := filter.Check("fornax").Body; got != "fornax" {
+		t.Errorf("old term still masked: %q", got)
+	}
+	want := []moderation.Term{{Term: "sharbert", Action: moderation.ActionFlag}}
+	if got := filter.Terms(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadTerms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terms.txt")
+	contents := "# banned words\nkerfuffle\n\nspam, reject\nscam,flag\n"
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	terms, err := moderation.LoadTerms(path)
+	if err != nil {
+		t.Fatalf("LoadTerms returned error: %v", err)
+	}
+	want := []moderation.Term{
+		{Term: "kerfuffle", Action: moderation.ActionMask},
+		{Term: "spam", Action: moderation.ActionReject},
+		{Term: "scam", Action: moderation.ActionFlag},
+	}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("LoadTerms = %+v, want %+v", terms, want)
+	}
+
+	if err := os.WriteFile(path, []byte("spam,explode\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := moderation.LoadTerms(path); err == nil {
+		t.Error("expected error for unknown action")
+	}
+
+	if err := os.WriteFile(path, []byte("bad word,reject\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := moderation.LoadTerms(path); err == nil {
+		t.Error("expected error for a term of more than one word")
+	}
+}
+
+func TestParseTerm(t *testing.T) {
+	if term, err := moderation.ParseTerm("  K3rfuffle "); err != nil || term != "kerfuffle" {
+		t.Errorf("ParseTerm = %q, %v; want kerfuffle", term, err)
+	}
+	for _, in := range []string{"bad word", "bad\tword", "", "..."} {
+		if term, err := moderation.ParseTerm(in); err == nil {
+			t.Errorf("ParseTerm(%q) = %q, expected an error", in, term)
+		}
+	}
+}
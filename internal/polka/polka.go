@@ -0,0 +1,134 @@
+// Package polka checks that webhook deliveries really come from Polka.
+//
+// Each delivery carries the Unix time it was sent in X-Polka-Timestamp, a
+// unique ID in X-Polka-Delivery, and in X-Polka-Signature one or more
+// comma-separated "sha256=<hex>" HMAC-SHA256 signatures of the timestamp, a
+// dot, the delivery ID, another dot and the raw request body. Signing the ID
+// means a captured delivery can't be sent again under a new one to get past
+// deduplication.
+package polka
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TimestampHeader = "X-Polka-Timestamp"
+	SignatureHeader = "X-Polka-Signature"
+	DeliveryHeader  = "X-Polka-Delivery"
+)
+
+const signaturePrefix = "sha256="
+
+var (
+	ErrMissingHeader    = errors.New("missing webhook header")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// Delivery identifies a verified webhook delivery.
+type Delivery struct {
+	ID     string
+	SentAt time.Time
+}
+
+// Verifier checks webhook signatures against every active secret, so that a
+// new secret can be added before Polka starts using it and the old one
+// removed after.
+type Verifier struct {
+	secrets   [][]byte
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithClock makes the verifier compare timestamps with now instead of the
+// system clock.
+func WithClock(now func() time.Time) Option {
+	return func(v *Verifier) { v.now = now }
+}
+
+// NewVerifier returns a Verifier accepting signatures made with any of the
+// non-empty secrets and timestamps within tolerance of the current time.
+// With no secrets it rejects every delivery.
+func NewVerifier(secrets []string, tolerance time.Duration, opts ...Option) *Verifier {
+	v := &Verifier{tolerance: tolerance, now: time.Now}
+	for _, opt := range opts {
+		opt(v)
+	}
+	for _, secret := range secrets {
+		if len(secret) != 0 {
+			v.secrets = append(v.secrets, []byte(secret))
+		}
+	}
+	return v
+}
+
+// Verify checks a delivery's headers and raw body. Timestamps too far from
+// the current time are refused, which limits how long a captured delivery
+// can be replayed; callers should also refuse delivery IDs they've seen.
+func (v *Verifier) Verify(header http.Header, body []byte) (Delivery, error) {
+	return v.VerifyAt(header, body, v.now())
+}
+
+// VerifyAt checks a delivery as if it had been received at the given time,
+// which lets a stored delivery be checked again when it is replayed.
+func (v *Verifier) VerifyAt(header http.Header, body []byte, receivedAt time.Time) (Delivery, error) {
+	id := header.Get(DeliveryHeader)
+	timestamp := header.Get(TimestampHeader)
+	signatures := header.Get(SignatureHeader)
+	if len(id) == 0 || len(timestamp) == 0 || len(signatures) == 0 {
+		return Delivery{}, ErrMissingHeader
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Delivery{}, ErrStaleTimestamp
+	}
+	sentAt := time.Unix(seconds, 0)
+	if age := receivedAt.Sub(sentAt); age > v.tolerance || age < -v.tolerance {
+		return Delivery{}, ErrStaleTimestamp
+	}
+
+	for _, signature := range strings.Split(signatures, ",") {
+		mac, ok := strings.CutPrefix(strings.TrimSpace(signature), signaturePrefix)
+		if !ok {
+			continue
+		}
+		got, err := hex.DecodeString(mac)
+		if err != nil {
+			continue
+		}
+		for _, secret := range v.secrets {
+			if hmac.Equal(got, sign(secret, timestamp, id, body)) {
+				return Delivery{ID: id, SentAt: sentAt}, nil
+			}
+		}
+	}
+	return Delivery{}, ErrInvalidSignature
+}
+
+// Sign returns the signature header value for a delivery sent at the given
+// time, as Polka computes it.
+func Sign(secret, deliveryID string, sentAt time.Time, body []byte) string {
+	return signaturePrefix + hex.EncodeToString(sign([]byte(secret), strconv.FormatInt(sentAt.Unix(), 10), deliveryID, body))
+}
+
+func sign(secret []byte, timestamp, deliveryID string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(deliveryID))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
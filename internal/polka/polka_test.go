@@ -0,0 +1,112 @@
+package polka_test
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/polka"
+)
+
+func deliveryHeader(id string, sentAt time.Time, signature string) http.Header {
+	header := http.Header{}
+	header.Set(polka.DeliveryHeader, id)
+	header.Set(polka.TimestampHeader, strconv.FormatInt(sentAt.Unix(), 10))
+	header.Set(polka.SignatureHeader, signature)
+	return header
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	verifier := polka.NewVerifier([]string{"current", "previous"}, 5*time.Minute, polka.WithClock(func() time.Time { return now }))
+	body := []byte(`{"event":"user.upgraded","data":{"user_id":"3311741c-680c-4546-99f3-fc9efac2036c"}}`)
+
+	delivery, err := verifier.Verify(deliveryHeader("evt_1", now, polka.Sign("current", "evt_1", now, body)), body)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if delivery.ID != "evt_1" || !delivery.SentAt.Equal(now) {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+
+	if _, err := verifier.Verify(deliveryHeader("evt_2", now, polka.Sign("previous", "evt_2", now, body)), body); err != nil {
+		t.Errorf("expected the previous secret to be accepted during rotation, got %v", err)
+	}
+
+	both := polka.Sign("retired", "evt_3", now, body) + ", " + polka.Sign("current", "evt_3", now, body)
+	if _, err := verifier.Verify(deliveryHeader("evt_3", now, both), body); err != nil {
+		t.Errorf("expected any matching signature in the list to be accepted, got %v", err)
+	}
+
+	skewed := now.Add(-4 * time.Minute)
+	if _, err := verifier.Verify(deliveryHeader("evt_4", skewed, polka.Sign("current", "evt_4", skewed, body)), body); err != nil {
+		t.Errorf("expected a timestamp within tolerance to be accepted, got %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	verifier := polka.NewVerifier([]string{"current", ""}, 5*time.Minute, polka.WithClock(func() time.Time { return now }))
+	body := []byte(`{"event":"user.upgraded"}`)
+	stale := now.Add(-6 * time.Minute)
+	future := now.Add(6 * time.Minute)
+
+	cases := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		want   error
+	}{
+		{"wrong secret", deliveryHeader("evt", now, polka.Sign("guess", "evt", now, body)), body, polka.ErrInvalidSignature},
+		{"empty secret", deliveryHeader("evt", now, polka.Sign("", "evt", now, body)), body, polka.ErrInvalidSignature},
+		{"tampered body", deliveryHeader("evt", now, polka.Sign("current", "evt", now, body)), []byte(`{"event":"user.upgraded "}`), polka.ErrInvalidSignature},
+		{"delivery ID not signed", deliveryHeader("evt_replayed", now, polka.Sign("current", "evt", now, body)), body, polka.ErrInvalidSignature},
+		{"timestamp not signed", deliveryHeader("evt", now.Add(time.Second), polka.Sign("current", "evt", now, body)), body, polka.ErrInvalidSignature},
+		{"missing prefix", deliveryHeader("evt", now, polka.Sign("current", "evt", now, body)[len("sha256="):]), body, polka.ErrInvalidSignature},
+		{"stale", deliveryHeader("evt", stale, polka.Sign("current", "evt", stale, body)), body, polka.ErrStaleTimestamp},
+		{"future", deliveryHeader("evt", future, polka.Sign("current", "evt", future, body)), body, polka.ErrStaleTimestamp},
+		{"missing delivery ID", deliveryHeader("", now, polka.Sign("current", "", now, body)), body, polka.ErrMissingHeader},
+		{"missing signature", deliveryHeader("evt", now, ""), body, polka.ErrMissingHeader},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := verifier.Verify(tc.header, tc.body); !errors.Is(err, tc.want) {
+				t.Errorf("Verify error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	header := deliveryHeader("evt", now, polka.Sign("current", "evt", now, body))
+	header.Set(polka.TimestampHeader, "yesterday")
+	if _, err := verifier.Verify(header, body); !errors.Is(err, polka.ErrStaleTimestamp) {
+		t.Errorf("expected unparseable timestamp to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyWithoutSecrets(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{}`)
+	verifier := polka.NewVerifier(nil, time.Minute)
+	if _, err := verifier.Verify(deliveryHeader("evt", now, polka.Sign("", "evt", now, body)), body); !errors.Is(err, polka.ErrInvalidSignature) {
+		t.Errorf("expected every delivery to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyAt(t *testing.T) {
+	sentAt := time.Unix(1_700_000_000, 0)
+	verifier := polka.NewVerifier([]string{"current"}, 5*time.Minute, polka.WithClock(func() time.Time { return sentAt.Add(24 * time.Hour) }))
+	body := []byte(`{"event":"user.upgraded"}`)
+	header := deliveryHeader("evt", sentAt, polka.Sign("current", "evt", sentAt, body))
+
+	if _, err := verifier.Verify(header, body); !errors.Is(err, polka.ErrStaleTimestamp) {
+		t.Fatalf("expected a day-old delivery to be stale, got %v", err)
+	}
+	if _, err := verifier.VerifyAt(header, body, sentAt.Add(time.Second)); err != nil {
+		t.Errorf("expected the delivery to verify at the time it was received, got %v", err)
+	}
+	if _, err := verifier.VerifyAt(header, []byte(`{}`), sentAt); !errors.Is(err, polka.ErrInvalidSignature) {
+		t.Errorf("expected a different body to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package ratelimit counts requests per key in fixed time windows.
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/ttlcache"
+)
+
+// Limiter allows each key up to a limit of requests per window. Counts are
+// kept in memory, so each server instance limits separately.
+type Limiter struct {
+	window time.Duration
+	counts *ttlcache.Cache[string, windowCount]
+}
+
+type windowCount struct {
+	start time.Time
+	count int
+}
+
+// Option configures a Limiter.
+type Option func(*config)
+
+type config struct {
+	now func() time.Time
+}
+
+// WithClock makes the limiter start and end windows by now instead of the
+// system clock.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) { c.now = now }
+}
+
+func New(window time.Duration, opts ...Option) *Limiter {
+	c := config{now: time.Now}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &Limiter{window: window, counts: ttlcache.New[string, windowCount](c.now)}
+}
+
+// Allow counts a request for key and reports whether it is within limit. If
+// it isn't, it also returns how long until the key's window ends.
+func (l *Limiter) Allow(key string, limit int) (bool, time.Duration) {
+	allowed := false
+	var wait time.Duration
+	l.counts.Update(key, func(now time.Time, c windowCount, found bool) (windowCount, time.Time) {
+		if !found {
+			c = windowCount{start: now}
+		}
+		end := c.start.Add(l.window)
+		if c.count >= limit {
+			wait = end.Sub(now)
+		} else {
+			c.count++
+			allowed = true
+		}
+		return c, end
+	})
+	return allowed, wait
+}
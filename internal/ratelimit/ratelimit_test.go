@@ -0,0 +1,35 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/ratelimit"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := ratelimit.New(time.Minute, ratelimit.WithClock(func() time.Time { return now }))
+
+	for i := range 3 {
+		if ok, _ := limiter.Allow("alice", 3); !ok {
+			t.Fatalf("request %d: expected to be allowed", i+1)
+		}
+	}
+	now = now.Add(20 * time.Second)
+	ok, wait := limiter.Allow("alice", 3)
+	if ok {
+		t.Fatal("expected the fourth request in the window to be refused")
+	}
+	if wait != 40*time.Second {
+		t.Errorf("expected to wait until the window ends, got %s", wait)
+	}
+	if ok, _ := limiter.Allow("bob", 3); !ok {
+		t.Error("expected keys to be limited separately")
+	}
+
+	now = now.Add(40 * time.Second)
+	if ok, _ := limiter.Allow("alice", 3); !ok {
+		t.Error("expected a new window to allow requests again")
+	}
+}
@@ -0,0 +1,25 @@
+// Package subscription holds the rules for how Chirpy Red subscriptions move
+// between statuses.
+package subscription
+
+import (
+	"database/sql"
+	"time"
+)
+
+// IsRed reports whether a subscription in the given status still grants
+// Chirpy Red. Past-due subscriptions keep it through their grace period.
+func IsRed(status string) bool {
+	return status == "active" || status == "past_due"
+}
+
+// GraceUntil returns when a subscription whose payment failed at failedAt
+// loses Chirpy Red. One that is already past due keeps the deadline set by
+// its first failure, so that failures that keep coming can't hold off
+// expiry.
+func GraceUntil(status string, graceUntil sql.NullTime, failedAt time.Time, gracePeriod time.Duration) time.Time {
+	if status == "past_due" && graceUntil.Valid {
+		return graceUntil.Time
+	}
+	return failedAt.Add(gracePeriod)
+}
@@ -0,0 +1,32 @@
+package subscription_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/subscription"
+)
+
+func TestIsRed(t *testing.T) {
+	for status, want := range map[string]bool{"active": true, "past_due": true, "canceled": false, "expired": false} {
+		if got := subscription.IsRed(status); got != want {
+			t.Errorf("IsRed(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestGraceUntil(t *testing.T) {
+	grace := 72 * time.Hour
+	first := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	deadline := subscription.GraceUntil("active", sql.NullTime{}, first, grace)
+	if want := first.Add(grace); !deadline.Equal(want) {
+		t.Fatalf("first failure: grace until %s, want %s", deadline, want)
+	}
+
+	second := first.Add(24 * time.Hour)
+	if got := subscription.GraceUntil("past_due", sql.NullTime{Time: deadline, Valid: true}, second, grace); !got.Equal(deadline) {
+		t.Errorf("second failure moved the grace period from %s to %s", deadline, got)
+	}
+}
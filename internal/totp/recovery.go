@@ -0,0 +1,39 @@
+package totp
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// GenerateRecoveryCodes returns n random single-use codes of the form
+// xxxxx-xxxxx, drawn from an alphabet without look-alike characters.
+func GenerateRecoveryCodes(n int) []string {
+	codes := make([]string, n)
+	buf := make([]byte, 10)
+	for i := range codes {
+		rand.Read(buf)
+		var b strings.Builder
+		for j, c := range buf {
+			if j == 5 {
+				b.WriteByte('-')
+			}
+			// 256 isn't a multiple of the alphabet size, which biases a
+			// few characters slightly; at 50 bits per code it doesn't matter.
+			b.WriteByte(recoveryAlphabet[int(c)%len(recoveryAlphabet)])
+		}
+		codes[i] = b.String()
+	}
+	return codes
+}
+
+// NormalizeRecoveryCode puts a recovery code typed by a user into the form
+// it was issued in, ignoring case, spaces and dashes.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+	if len(code) == 10 {
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
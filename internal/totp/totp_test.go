@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 Appendix B for HMAC-SHA1.
+func TestRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tc := range cases {
+		step := Step(time.Unix(tc.unix, 0))
+		if got := hotp(key, uint64(step), 8); got != tc.want {
+			t.Errorf("hotp at %d = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestCodeAndValidate(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(59, 0)
+
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "287082" {
+		t.Errorf("Code = %s, want 287082", code)
+	}
+
+	step, ok := Validate(secret, code, now, 1)
+	if !ok || step != 1 {
+		t.Errorf("Validate = %d, %v; want step 1", step, ok)
+	}
+	if _, ok := Validate(secret, code, now.Add(Period), 1); !ok {
+		t.Error("expected code from the previous step to be accepted with skew 1")
+	}
+	if _, ok := Validate(secret, code, now.Add(2*Period), 1); ok {
+		t.Error("expected code from two steps ago to be rejected")
+	}
+	if _, ok := Validate(secret, "123456", now, 1); ok {
+		t.Error("expected wrong code to be rejected")
+	}
+	if _, ok := Validate(secret, "28708", now, 1); ok {
+		t.Error("expected short code to be rejected")
+	}
+	if _, ok := Validate("not base32!", code, now, 1); ok {
+		t.Error("expected invalid secret to be rejected")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret := GenerateSecret()
+	if len(secret) != 32 {
+		t.Errorf("expected 32 base32 characters, got %q", secret)
+	}
+	if _, err := Code(secret, time.Now()); err != nil {
+		t.Errorf("generated secret is unusable: %v", err)
+	}
+	if GenerateSecret() == secret {
+		t.Error("expected distinct secrets")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("JBSWY3DPEHPK3PXP", "Chirpy", "walt@breakingbad.com")
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" || parsed.Path != "/Chirpy:walt@breakingbad.com" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Chirpy" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes := GenerateRecoveryCodes(10)
+	pattern := regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !pattern.MatchString(code) {
+			t.Errorf("unexpected recovery code format %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+		if got := NormalizeRecoveryCode(" " + code[:5] + " " + code[6:] + " "); got != code {
+			t.Errorf("NormalizeRecoveryCode = %q, want %q", got, code)
+		}
+	}
+	if got := NormalizeRecoveryCode("ABCDE-FGHJK"); got != "abcde-fghjk" {
+		t.Errorf("NormalizeRecoveryCode ignored case: %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+// Package ttlcache is an in-memory map whose entries expire.
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+// A sweep for expired entries runs once the cache holds this many entries,
+// and after that whenever it has grown to twice its size after the last
+// sweep, so that sweeping costs O(1) per update on average.
+const sweepSize = 1024
+
+// Cache maps keys to values until each entry's expiry time. Expired entries
+// are never returned, and are removed when looked up or swept. It is safe
+// for concurrent use.
+type Cache[K comparable, V any] struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[K]entry[V]
+	nextSweep int
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// New returns an empty cache that uses now as its clock.
+func New[K comparable, V any](now func() time.Time) *Cache[K, V] {
+	return &Cache[K, V]{now: now, entries: make(map[K]entry[V]), nextSweep: sweepSize}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Update replaces the entry for key with what update returns, given the
+// current time and the current value, if there is one that hasn't expired.
+// Reading and replacing the entry happen under one lock.
+func (c *Cache[K, V]) Update(key K, update func(now time.Time, current V, found bool) (V, time.Time)) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= c.nextSweep {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = max(2*len(c.entries), sweepSize)
+	}
+
+	current, found := c.entries[key]
+	if found && !now.Before(current.expires) {
+		current, found = entry[V]{}, false
+	}
+	value, expires := update(now, current.value, found)
+	c.entries[key] = entry[V]{value: value, expires: expires}
+	return value
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,93 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+)
+
+func set(c *Cache[int, string], key int, value string, ttl time.Duration) {
+	c.Update(key, func(now time.Time, _ string, _ bool) (string, time.Time) {
+		return value, now.Add(ttl)
+	})
+}
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	cache := New[int, string](func() time.Time { return now })
+
+	if _, ok := cache.Get(1); ok {
+		t.Fatal("expected miss for unknown key")
+	}
+	set(cache, 1, "a", time.Minute)
+	if value, ok := cache.Get(1); !ok || value != "a" {
+		t.Fatalf("Get = %q, %v; want a", value, ok)
+	}
+
+	cache.Update(1, func(_ time.Time, current string, found bool) (string, time.Time) {
+		if !found || current != "a" {
+			t.Errorf("Update saw %q, %v; want a", current, found)
+		}
+		return current + "b", now.Add(time.Minute)
+	})
+	if value, _ := cache.Get(1); value != "ab" {
+		t.Errorf("expected Update to replace the value, got %q", value)
+	}
+
+	cache.Delete(1)
+	if _, ok := cache.Get(1); ok {
+		t.Error("expected miss after Delete")
+	}
+
+	set(cache, 1, "c", time.Minute)
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get(1); ok {
+		t.Error("expected entry to expire")
+	}
+	set(cache, 2, "d", time.Minute)
+	now = now.Add(time.Minute)
+	cache.Update(2, func(_ time.Time, current string, found bool) (string, time.Time) {
+		if found || current != "" {
+			t.Errorf("expected Update not to see an expired value, got %q, %v", current, found)
+		}
+		return "e", now.Add(time.Minute)
+	})
+}
+
+func TestCacheSweepsExpiredEntries(t *testing.T) {
+	now := time.Now()
+	cache := New[int, string](func() time.Time { return now })
+	for i := range sweepSize - 1 {
+		set(cache, i, "old", time.Minute)
+	}
+
+	now = now.Add(time.Hour)
+	set(cache, -1, "new", time.Minute)
+	if len(cache.entries) != sweepSize {
+		t.Fatalf("expected no sweep below %d entries, have %d", sweepSize, len(cache.entries))
+	}
+	set(cache, -2, "new", time.Minute)
+	if len(cache.entries) != 2 {
+		t.Errorf("expected expired entries to be swept, %d left", len(cache.entries))
+	}
+}
+
+func TestCacheSweepsAfterDoubling(t *testing.T) {
+	now := time.Now()
+	cache := New[int, string](func() time.Time { return now })
+	for i := range 1500 {
+		set(cache, i, "old", time.Hour)
+	}
+	// The sweep at sweepSize found nothing expired, so the next one waits
+	// until the cache has doubled.
+	now = now.Add(2 * time.Hour)
+	for i := range 548 {
+		set(cache, -1-i, "new", time.Hour)
+	}
+	if len(cache.entries) != 2*sweepSize {
+		t.Fatalf("expected no sweep before the cache doubles, have %d", len(cache.entries))
+	}
+	set(cache, -1000, "new", time.Hour)
+	if len(cache.entries) != 549 {
+		t.Errorf("expected the entries set before the clock moved on to be swept, %d left", len(cache.entries))
+	}
+}
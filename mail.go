@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/mailer"
+)
+
+// sendMail delivers msg in the background so that a slow mail server
+// doesn't hold up the request. Failures are only logged.
+func (cfg *apiConfig) sendMail(msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := cfg.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Error sending mail to %s: %s", msg.To, err)
+		}
+	}()
+}
+
+func linkWithToken(base, token string) string {
+	link, err := url.Parse(base)
+	if err != nil {
+		return base + token
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
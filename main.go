@@ -1,22 +1,129 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/louiehdev/chirpy/internal/auth"
 	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/entitlements"
+	"github.com/louiehdev/chirpy/internal/mailer"
+	"github.com/louiehdev/chirpy/internal/moderation"
+	"github.com/louiehdev/chirpy/internal/polka"
+	"github.com/louiehdev/chirpy/internal/ratelimit"
 )
 
 func main() {
 	godotenv.Load()
 	dbURL := os.Getenv("DB_URL")
+	if len(os.Args) > 1 {
+		if err := runCommand(dbURL, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	platform := os.Getenv("PLATFORM")
 	secret := os.Getenv("SECRET")
-	polkaKey := os.Getenv("POLKA_KEY")
+	polkaSecrets := listFromEnv("POLKA_WEBHOOK_SECRETS")
+	if len(polkaSecrets) == 0 {
+		polkaSecrets = []string{os.Getenv("POLKA_KEY")}
+	}
+	polkaTolerance, err := durationFromEnv("POLKA_WEBHOOK_TOLERANCE", 5*time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokenVersionTTL, err := durationFromEnv("TOKEN_VERSION_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	catalog, err := catalogFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	keys := auth.NewHMACKeyStore(secret)
+	if dir := os.Getenv("JWT_KEYS_DIR"); len(dir) != 0 {
+		// SECRET still signs one-time tokens, so HS256 access tokens are
+		// only accepted alongside the keys when JWT_HMAC_SECRET is set.
+		if keys, err = auth.LoadKeyStore(dir, os.Getenv("JWT_SIGNING_KEY"), os.Getenv("JWT_HMAC_SECRET")); err != nil {
+			log.Fatal(err)
+		}
+	}
+	validation := auth.DefaultValidationOptions()
+	if issuer := os.Getenv("JWT_ISSUER"); len(issuer) != 0 {
+		validation.Issuer = issuer
+	}
+	validation.Audiences = listFromEnv("JWT_AUDIENCES")
+	validation.Algorithms = listFromEnv("JWT_ALGORITHMS")
+	if validation.Leeway, err = durationFromEnv("JWT_LEEWAY", 0); err != nil {
+		log.Fatal(err)
+	}
+	if claims := listFromEnv("JWT_REQUIRED_CLAIMS"); len(claims) != 0 {
+		validation.RequiredClaims = claims
+	}
+	if err := keys.SetValidation(validation); err != nil {
+		log.Fatal(err)
+	}
+
+	requireVerifiedEmail, err := boolFromEnv("REQUIRE_VERIFIED_EMAIL", false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	verificationResendInterval, err := durationFromEnv("VERIFICATION_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
+	passwordResetInterval, err := durationFromEnv("PASSWORD_RESET_INTERVAL", time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
+	accountLockout := auth.DefaultAccountLockout()
+	if accountLockout.Threshold, err = intFromEnv("LOGIN_LOCKOUT_THRESHOLD", accountLockout.Threshold); err != nil {
+		log.Fatal(err)
+	}
+	ipLockout := auth.DefaultIPLockout()
+	if ipLockout.Threshold, err = intFromEnv("LOGIN_IP_LOCKOUT_THRESHOLD", ipLockout.Threshold); err != nil {
+		log.Fatal(err)
+	}
+	lockoutDuration, err := durationFromEnv("LOGIN_LOCKOUT_DURATION", accountLockout.LockoutDuration)
+	if err != nil {
+		log.Fatal(err)
+	}
+	accountLockout.LockoutDuration = lockoutDuration
+	ipLockout.LockoutDuration = lockoutDuration
+	subscriptionPeriod, err := durationFromEnv("SUBSCRIPTION_PERIOD", 30*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	subscriptionGracePeriod, err := durationFromEnv("SUBSCRIPTION_GRACE_PERIOD", 3*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	subscriptionSweepInterval, err := durationFromEnv("SUBSCRIPTION_SWEEP_INTERVAL", 10*time.Minute)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if subscriptionSweepInterval <= 0 {
+		log.Fatal("SUBSCRIPTION_SWEEP_INTERVAL must be positive")
+	}
+	mail, err := mailerFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	baseModerationTerms := defaultModerationTerms
+	if path := os.Getenv("MODERATION_TERMS_FILE"); len(path) != 0 {
+		if baseModerationTerms, err = moderation.LoadTerms(path); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
@@ -24,30 +131,134 @@ func main() {
 	}
 	dbQueries := database.New(db)
 
-	cfg := apiConfig{db: dbQueries, platform: platform, secret: secret, polkaKey: polkaKey}
+	cfg := apiConfig{
+		db:       dbQueries,
+		conn:     db,
+		platform: platform,
+		keys:     keys,
+		polka:    polka.NewVerifier(polkaSecrets, polkaTolerance),
+
+		entitlements:   entitlements.NewResolver(catalog, dbQueries),
+		apiRateLimiter: ratelimit.New(apiRateWindow),
+
+		moderator:           moderation.NewFilter(baseModerationTerms),
+		baseModerationTerms: baseModerationTerms,
+
+		tokenVersions: auth.NewVersionCache(tokenVersionTTL),
+
+		secret:                     secret,
+		mailer:                     mail,
+		verifyEmailURL:             os.Getenv("VERIFY_EMAIL_URL"),
+		requireVerifiedEmail:       requireVerifiedEmail,
+		verificationResendInterval: verificationResendInterval,
+		passwordResetURL:           os.Getenv("PASSWORD_RESET_URL"),
+		passwordResetInterval:      passwordResetInterval,
+
+		accountLockout: accountLockout,
+		ipLockout:      ipLockout,
+
+		subscriptionPeriod:      subscriptionPeriod,
+		subscriptionGracePeriod: subscriptionGracePeriod,
+	}
+	if err := cfg.reloadModerationTerms(context.Background()); err != nil {
+		log.Printf("Error loading moderation terms: %s", err)
+	}
 
 	mux := http.NewServeMux()
 	handler := http.StripPrefix("/app", http.FileServer(http.Dir("")))
 
 	mux.Handle("/app/", cfg.middlewareMetricsInc(handler))
 	mux.HandleFunc("GET /api/healthz", cfg.healthHandler)
-	mux.HandleFunc("GET /admin/metrics", cfg.metricsHandler)
+	mux.HandleFunc("GET /.well-known/jwks.json", cfg.jwksHandler)
+	mux.HandleFunc("GET /admin/metrics", cfg.requireRole(auth.RoleAdmin, cfg.metricsHandler))
 	mux.HandleFunc("GET /api/chirps", cfg.getChirpsHandler)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", cfg.getChirpFromIDHandler)
-	mux.HandleFunc("POST /admin/reset", cfg.resetHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", cfg.threadHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", cfg.getChirpRevisionsHandler)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", cfg.editChirpHandler)
+	mux.HandleFunc("POST /admin/reset", cfg.requireRole(auth.RoleAdmin, cfg.resetHandler))
+	mux.HandleFunc("GET /admin/users", cfg.requireRole(auth.RoleAdmin, cfg.adminListUsersHandler))
+	mux.HandleFunc("GET /admin/users/{userID}", cfg.requireRole(auth.RoleAdmin, cfg.adminGetUserHandler))
+	mux.HandleFunc("PUT /admin/users/{userID}/role", cfg.requireRole(auth.RoleAdmin, cfg.adminSetUserRoleHandler))
+	mux.HandleFunc("DELETE /admin/users/{userID}", cfg.requireRole(auth.RoleAdmin, cfg.adminDeleteUserHandler))
+	mux.HandleFunc("GET /admin/moderation/terms", cfg.requireRole(auth.RoleModerator, cfg.getModerationTermsHandler))
+	mux.HandleFunc("PUT /admin/moderation/terms", cfg.requireRole(auth.RoleModerator, cfg.putModerationTermHandler))
+	mux.HandleFunc("DELETE /admin/moderation/terms/{term}", cfg.requireRole(auth.RoleModerator, cfg.deleteModerationTermHandler))
+	mux.HandleFunc("GET /admin/moderation/flags", cfg.requireRole(auth.RoleModerator, cfg.getModerationFlagsHandler))
+	mux.HandleFunc("POST /admin/moderation/flags/{flagID}/resolve", cfg.requireRole(auth.RoleModerator, cfg.resolveModerationFlagHandler))
+	mux.HandleFunc("POST /admin/login/unlock", cfg.requireRole(auth.RoleAdmin, cfg.unlockLoginHandler))
+	mux.HandleFunc("GET /admin/webhooks", cfg.requireRole(auth.RoleAdmin, cfg.adminListWebhooksHandler))
+	mux.HandleFunc("GET /admin/webhooks/{webhookID}", cfg.requireRole(auth.RoleAdmin, cfg.adminGetWebhookHandler))
+	mux.HandleFunc("POST /admin/webhooks/{webhookID}/replay", cfg.requireRole(auth.RoleAdmin, cfg.adminReplayWebhookHandler))
 	mux.HandleFunc("POST /api/login", cfg.loginHandler)
+	mux.HandleFunc("POST /api/login/mfa", cfg.loginMFAHandler)
+	mux.HandleFunc("POST /api/mfa/totp", cfg.enrollTOTPHandler)
+	mux.HandleFunc("POST /api/mfa/totp/confirm", cfg.confirmTOTPHandler)
+	mux.HandleFunc("DELETE /api/mfa/totp", cfg.disableTOTPHandler)
+	mux.HandleFunc("POST /api/mfa/recovery-codes", cfg.regenerateRecoveryCodesHandler)
 	mux.HandleFunc("POST /api/refresh", cfg.refreshHandler)
 	mux.HandleFunc("POST /api/revoke", cfg.revokeHandler)
+	mux.HandleFunc("GET /api/tokens", cfg.getAPITokensHandler)
+	mux.HandleFunc("POST /api/tokens", cfg.createAPITokenHandler)
+	mux.HandleFunc("DELETE /api/tokens/{tokenID}", cfg.deleteAPITokenHandler)
+	mux.HandleFunc("GET /api/sessions", cfg.getSessionsHandler)
+	mux.HandleFunc("DELETE /api/sessions", cfg.deleteSessionsHandler)
+	mux.HandleFunc("DELETE /api/sessions/{sessionID}", cfg.deleteSessionHandler)
 	mux.HandleFunc("POST /api/chirps", cfg.chirpHandler)
 	mux.HandleFunc("POST /api/users", cfg.createUserHandler)
-	mux.HandleFunc("POST /api/polka/webhooks", cfg.upgradeUserHandler)
+	mux.HandleFunc("POST /api/users/verify", cfg.verifyEmailHandler)
+	mux.HandleFunc("POST /api/users/verify/resend", cfg.resendVerificationHandler)
+	mux.HandleFunc("POST /api/password/forgot", cfg.forgotPasswordHandler)
+	mux.HandleFunc("POST /api/password/reset", cfg.resetPasswordHandler)
+	mux.HandleFunc("POST /api/polka/webhooks", cfg.polkaWebhookHandler)
 	mux.HandleFunc("PUT /api/users", cfg.updateUserHandler)
+	mux.HandleFunc("GET /api/users/me/entitlements", cfg.getEntitlementsHandler)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.deleteChirpHandler)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/likes", cfg.getChirpLikesHandler)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}/like", cfg.likeChirpHandler)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", cfg.unlikeChirpHandler)
+	mux.HandleFunc("GET /api/timeline", cfg.timelineHandler)
+	mux.HandleFunc("GET /api/search/chirps", cfg.searchChirpsHandler)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", cfg.getHashtagChirpsHandler)
+	mux.HandleFunc("GET /api/trending", cfg.trendingHandler)
+	mux.HandleFunc("GET /api/users/{userID}/followers", cfg.getFollowersHandler)
+	mux.HandleFunc("GET /api/users/{userID}/following", cfg.getFollowingHandler)
+	mux.HandleFunc("PUT /api/users/{userID}/follow", cfg.followHandler)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.unfollowHandler)
 
 	server := http.Server{
 		Addr:    ":8080",
 		Handler: mux,
 	}
 
+	go cfg.runSubscriptionSweeper(context.Background(), subscriptionSweepInterval)
 	log.Fatal(server.ListenAndServe())
 }
+
+// mailerFromEnv picks how mail is delivered: MAILER=smtp sends through
+// SMTP_HOST, MAILER=file writes messages to MAIL_DIR, and the default logs
+// them.
+func mailerFromEnv() (mailer.Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if len(from) == 0 {
+		from = "chirpy@localhost"
+	}
+	switch kind := os.Getenv("MAILER"); kind {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if len(port) == 0 {
+			port = "587"
+		}
+		return mailer.NewSMTPMailer(os.Getenv("SMTP_HOST"), port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from), nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if len(dir) == 0 {
+			dir = "mail"
+		}
+		return mailer.NewFileMailer(dir, from)
+	case "", "log":
+		return mailer.NewLogMailer(os.Stderr, from), nil
+	default:
+		return nil, fmt.Errorf("unknown MAILER %q", kind)
+	}
+}
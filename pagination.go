@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 100
+)
+
+// pageCursor is the (created_at, id) keyset position of a row. Backward
+// cursors page towards the start of the listing instead of the end.
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+type pageRequest struct {
+	Limit  int
+	Desc   bool
+	Cursor *pageCursor
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func parsePageRequest(query url.Values, defaultDesc bool) (pageRequest, error) {
+	page := pageRequest{Limit: defaultPageLimit, Desc: defaultDesc}
+
+	switch query.Get("sort") {
+	case "":
+	case "asc":
+		page.Desc = false
+	case "desc":
+		page.Desc = true
+	default:
+		return page, fmt.Errorf("sort must be asc or desc")
+	}
+
+	if limit := query.Get("limit"); len(limit) != 0 {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return page, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		page.Limit = n
+	}
+
+	if cursor := query.Get("cursor"); len(cursor) != 0 {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return page, fmt.Errorf("invalid cursor")
+		}
+		page.Cursor = &c
+	}
+	return page, nil
+}
+
+// cursorArgs converts a cursor into the nullable keyset arguments taken by
+// the paginated queries.
+func cursorArgs(c *pageCursor) (sql.NullTime, uuid.NullUUID) {
+	if c == nil {
+		return sql.NullTime{}, uuid.NullUUID{}
+	}
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}, uuid.NullUUID{UUID: c.ID, Valid: true}
+}
+
+// fetchPage runs one keyset query for page and returns the rows in display
+// order along with the cursors for the neighbouring pages, if any. fetch must
+// return rows strictly after the cursor in ascending order when ascending is
+// true, and rows strictly before it in descending order otherwise.
+func fetchPage[T any](page pageRequest, key func(T) pageCursor, fetch func(ascending bool, cursor *pageCursor, limit int32) ([]T, error)) ([]T, *pageCursor, *pageCursor, error) {
+	backward := page.Cursor != nil && page.Cursor.Backward
+	items, err := fetch(page.Desc == backward, page.Cursor, int32(page.Limit+1))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	more := len(items) > page.Limit
+	if more {
+		items = items[:page.Limit]
+	}
+	if backward {
+		slices.Reverse(items)
+	}
+	if len(items) == 0 {
+		return items, nil, nil, nil
+	}
+
+	var next, prev *pageCursor
+	if more || backward {
+		last := key(items[len(items)-1])
+		next = &last
+	}
+	if page.Cursor != nil && (more || !backward) {
+		first := key(items[0])
+		first.Backward = true
+		prev = &first
+	}
+	return items, next, prev, nil
+}
+
+// setPageLinks advertises the neighbouring pages in an RFC 8288 Link header,
+// keeping every other query parameter of the current request.
+func setPageLinks(w http.ResponseWriter, r *http.Request, next, prev *pageCursor) {
+	var links []string
+	for _, link := range []struct {
+		rel    string
+		cursor *pageCursor
+	}{{"next", next}, {"prev", prev}} {
+		if link.cursor == nil {
+			continue
+		}
+		query := r.URL.Query()
+		query.Set("cursor", encodeCursor(*link.cursor))
+		target := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, target.String(), link.rel))
+	}
+	if len(links) != 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func chirpCursor(chirp database.Chirp) pageCursor {
+	return pageCursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
+}
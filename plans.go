@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/louiehdev/chirpy/internal/auth"
+	"github.com/louiehdev/chirpy/internal/entitlements"
+)
+
+// apiRateWindow is the window the plans' api_rate_limit counts requests in.
+const apiRateWindow = time.Minute
+
+type chirpLengthError struct {
+	Error  string `json:"error"`
+	Length int    `json:"length"`
+	Limit  int    `json:"limit"`
+}
+
+// entitlementsResponse leaves out media attachments and scheduled chirps,
+// which plans can set but which aren't features yet.
+type entitlementsResponse struct {
+	Plan           string                `json:"plan"`
+	MaxChirpLength int                   `json:"max_chirp_length"`
+	EditWindow     entitlements.Duration `json:"edit_window"`
+	APIRateLimit   int                   `json:"api_rate_limit"`
+}
+
+// legacyPlanSettings predate the plan file and adjust the built-in free and
+// red plans.
+var legacyPlanSettings = []string{"EDIT_WINDOW", "RED_EDIT_WINDOW", "CHIRP_LENGTH", "RED_CHIRP_LENGTH"}
+
+// catalogFromEnv loads ENTITLEMENTS_FILE, or else the built-in plans with
+// any of the older limit settings applied.
+func catalogFromEnv() (*entitlements.Catalog, error) {
+	if path := os.Getenv("ENTITLEMENTS_FILE"); len(path) != 0 {
+		for _, key := range legacyPlanSettings {
+			if len(os.Getenv(key)) != 0 {
+				return nil, fmt.Errorf("%s can't be used with ENTITLEMENTS_FILE; set the limit in the plan file instead", key)
+			}
+		}
+		return entitlements.LoadCatalog(path)
+	}
+
+	catalog := entitlements.DefaultCatalog()
+	for _, plan := range []struct{ name, prefix string }{{"free", ""}, {"red", "RED_"}} {
+		limits := catalog.Plan(plan.name)
+		editWindow, err := durationFromEnv(plan.prefix+"EDIT_WINDOW", time.Duration(limits.EditWindow))
+		if err != nil {
+			return nil, err
+		}
+		limits.EditWindow = entitlements.Duration(editWindow)
+		if limits.MaxChirpLength, err = intFromEnv(plan.prefix+"CHIRP_LENGTH", limits.MaxChirpLength); err != nil {
+			return nil, err
+		}
+		if err := catalog.SetPlan(limits); err != nil {
+			return nil, err
+		}
+	}
+	return catalog, nil
+}
+
+func (cfg *apiConfig) getEntitlementsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r, auth.ScopeAccountRead)
+	if err != nil {
+		respondUnauthorized(w, err)
+		return
+	}
+	limits, err := cfg.entitlements.ForUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, entitlementsResponse{
+		Plan:           limits.Plan,
+		MaxChirpLength: limits.MaxChirpLength,
+		EditWindow:     limits.EditWindow,
+		APIRateLimit:   limits.APIRateLimit,
+	})
+}
@@ -0,0 +1,33 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (id, user_id, name, token_hash, scopes, created_at, expires_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW(),
+    $5
+)
+RETURNING id, name, scopes, created_at, expires_at, last_used_at;
+
+-- name: ListAPITokens :many
+SELECT id, name, scopes, created_at, expires_at, last_used_at FROM api_tokens
+WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC;
+
+-- name: UseAPIToken :one
+UPDATE api_tokens
+SET last_used_at = NOW()
+WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING user_id, scopes;
+
+-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserAPITokens :exec
+UPDATE api_tokens
+SET revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,36 @@
+-- name: AddChirpHashtags :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT sqlc.arg('chirp_id'), unnest(sqlc.arg('tags')::text[]), sqlc.arg('created_at')
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: ListHashtagChirpsAfter :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+WHERE chirp_hashtags.tag = sqlc.arg('tag')
+AND chirps.deleted_at IS NULL
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (chirp_hashtags.created_at, chirp_hashtags.chirp_id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY chirp_hashtags.created_at ASC, chirp_hashtags.chirp_id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListHashtagChirpsBefore :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+WHERE chirp_hashtags.tag = sqlc.arg('tag')
+AND chirps.deleted_at IS NULL
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (chirp_hashtags.created_at, chirp_hashtags.chirp_id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY chirp_hashtags.created_at DESC, chirp_hashtags.chirp_id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: GetTrendingHashtags :many
+SELECT tag,
+    COUNT(*) AS uses,
+    SUM(EXP(-LN(2) * EXTRACT(EPOCH FROM (NOW() - created_at))::float8 / sqlc.arg('half_life_seconds')::float8))::float8 AS score
+FROM chirp_hashtags
+WHERE created_at > NOW() - make_interval(secs => sqlc.arg('window_seconds')::float8)
+GROUP BY tag
+ORDER BY score DESC, tag ASC
+LIMIT sqlc.arg('row_limit');
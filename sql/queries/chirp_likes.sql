@@ -0,0 +1,36 @@
+-- name: LikeChirp :exec
+INSERT INTO chirp_likes (chirp_id, user_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnlikeChirp :exec
+DELETE FROM chirp_likes
+WHERE chirp_id = $1 AND user_id = $2;
+
+-- name: ListChirpLikesAfter :many
+SELECT * FROM chirp_likes
+WHERE chirp_id = sqlc.arg('chirp_id')
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, user_id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, user_id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListChirpLikesBefore :many
+SELECT * FROM chirp_likes
+WHERE chirp_id = sqlc.arg('chirp_id')
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, user_id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, user_id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: CountLikesForChirps :many
+SELECT chirp_id, COUNT(*) AS like_count FROM chirp_likes
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY chirp_id;
+
+-- name: GetLikedChirpIDs :many
+SELECT chirp_id FROM chirp_likes
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
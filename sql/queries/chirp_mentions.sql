@@ -0,0 +1,8 @@
+-- name: AddChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, handle, created_at)
+SELECT sqlc.arg('chirp_id'), unnest(sqlc.arg('handles')::text[]), sqlc.arg('created_at')
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
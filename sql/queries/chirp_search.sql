@@ -0,0 +1,23 @@
+-- name: SearchChirps :many
+SELECT chirps.*,
+    ts_rank(chirp_search.document, query)::float8 AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(chirps.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5'
+    ) AS snippet
+FROM chirps
+JOIN chirp_search ON chirp_search.chirp_id = chirps.id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg('query')) AS query
+WHERE chirp_search.document @@ query
+AND chirps.deleted_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id'))
+AND (sqlc.narg('since')::timestamp IS NULL OR chirps.created_at >= sqlc.narg('since'))
+AND (sqlc.narg('until')::timestamp IS NULL OR chirps.created_at < sqlc.narg('until'))
+ORDER BY
+    CASE WHEN sqlc.arg('order_by_rank')::bool THEN ts_rank(chirp_search.document, query) END DESC,
+    chirps.created_at DESC,
+    chirps.id DESC
+LIMIT sqlc.arg('row_limit')
+OFFSET sqlc.arg('row_offset');
@@ -1,27 +1,130 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, rechirp_of, quote_of)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5
 )
 RETURNING *;
 
--- name: GetChirps :many
+-- name: ListChirpsAfter :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
+WHERE deleted_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('row_limit');
 
--- name: GetChirpsFromUser :many
+-- name: ListChirpsBefore :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
+WHERE deleted_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
 
 -- name: GetChirpFromID :one
 SELECT * FROM chirps
 WHERE id = $1;
 
+-- name: GetChirpsFromIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: GetRechirp :one
+SELECT * FROM chirps
+WHERE user_id = $1 AND rechirp_of = $2;
+
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.*, 1 AS depth FROM chirps
+    WHERE chirps.id = (SELECT c.in_reply_to FROM chirps c WHERE c.id = sqlc.arg('id'))
+    UNION ALL
+    SELECT chirps.*, ancestors.depth + 1 FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.in_reply_to
+)
+SELECT id, created_at, updated_at, body, user_id, in_reply_to, deleted_at, rechirp_of, quote_of FROM ancestors
+ORDER BY depth DESC;
+
+-- name: GetChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT chirps.*, 1 AS depth FROM chirps
+    WHERE chirps.in_reply_to = sqlc.arg('id')
+    UNION ALL
+    SELECT chirps.*, descendants.depth + 1 FROM chirps
+    JOIN descendants ON chirps.in_reply_to = descendants.id
+    WHERE descendants.depth < 100
+)
+SELECT id, created_at, updated_at, body, user_id, in_reply_to, deleted_at, rechirp_of, quote_of FROM descendants
+ORDER BY created_at ASC, id ASC
+LIMIT 1000;
+
+-- name: CountRepliesForChirps :many
+SELECT in_reply_to::uuid AS chirp_id, COUNT(*) AS reply_count FROM chirps
+WHERE in_reply_to = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND deleted_at IS NULL
+GROUP BY in_reply_to;
+
+-- name: CountRechirpsForChirps :many
+SELECT COALESCE(rechirp_of, quote_of)::uuid AS chirp_id, COUNT(rechirp_of) AS rechirp_count, COUNT(quote_of) AS quote_count FROM chirps
+WHERE (rechirp_of = ANY(sqlc.arg('chirp_ids')::uuid[]) OR quote_of = ANY(sqlc.arg('chirp_ids')::uuid[]))
+AND deleted_at IS NULL
+GROUP BY COALESCE(rechirp_of, quote_of);
+
+-- name: ChirpHasDependents :one
+SELECT EXISTS (
+    SELECT 1 FROM chirps
+    WHERE in_reply_to = sqlc.arg('id')::uuid
+    OR rechirp_of = sqlc.arg('id')::uuid
+    OR quote_of = sqlc.arg('id')::uuid
+);
+
+-- name: UpdateChirpBody :one
+WITH revision AS (
+    INSERT INTO chirp_revisions (id, chirp_id, body, created_at, replaced_at)
+    SELECT gen_random_uuid(), chirps.id, chirps.body, chirps.updated_at, NOW() FROM chirps
+    WHERE chirps.id = sqlc.arg('id')
+)
+UPDATE chirps
+SET body = sqlc.arg('body'), updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: TombstoneChirp :exec
+UPDATE chirps
+SET body = '', deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
 -- name: DeleteChirp :exec
 DELETE FROM chirps
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: DeleteUnreferencedTombstone :execrows
+DELETE FROM chirps
+WHERE id = $1
+AND deleted_at IS NOT NULL
+AND NOT EXISTS (
+    SELECT 1 FROM chirps c
+    WHERE c.in_reply_to = $1 OR c.rechirp_of = $1 OR c.quote_of = $1
+);
+
+-- name: ListTimelineAfter :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND (user_id = sqlc.arg('user_id') OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListTimelineBefore :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND (user_id = sqlc.arg('user_id') OR user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg('user_id')))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
@@ -0,0 +1,30 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, user_id, email, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW(),
+    $4
+);
+
+-- name: GetLatestEmailVerificationTime :one
+SELECT MAX(created_at)::timestamp FROM email_verification_tokens
+WHERE user_id = $1;
+
+-- name: DeleteUnusedEmailVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: VerifyEmail :one
+WITH used AS (
+    UPDATE email_verification_tokens
+    SET used_at = NOW()
+    WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+    RETURNING user_id, email
+)
+UPDATE users
+SET email_verified_at = NOW(), updated_at = NOW()
+FROM used
+WHERE users.id = used.user_id AND users.email = used.email
+RETURNING users.id;
@@ -0,0 +1,24 @@
+-- name: EnsureLoginFailures :exec
+INSERT INTO login_failures (key, failures, last_failed_at, blocked_until)
+SELECT key, 0, sqlc.arg('now')::timestamp, sqlc.arg('now')::timestamp
+FROM UNNEST(sqlc.arg('keys')::text[]) AS key
+ON CONFLICT (key) DO NOTHING;
+
+-- name: LockLoginFailure :one
+SELECT * FROM login_failures
+WHERE key = $1
+FOR UPDATE;
+
+-- name: UpdateLoginFailure :exec
+UPDATE login_failures
+SET failures = $2, last_failed_at = $3, blocked_until = $4
+WHERE key = $1;
+
+-- name: RefundLoginAttempt :exec
+UPDATE login_failures
+SET failures = GREATEST(failures - 1, 0)
+WHERE key = $1;
+
+-- name: ClearLoginFailures :execrows
+DELETE FROM login_failures
+WHERE key = ANY(sqlc.arg('keys')::text[]);
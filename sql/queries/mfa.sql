@@ -0,0 +1,52 @@
+-- name: SetPendingTOTPSecret :execrows
+UPDATE users
+SET totp_secret = $1, totp_last_step = 0, updated_at = NOW()
+WHERE id = $2 AND totp_enabled_at IS NULL;
+
+-- name: EnableTOTP :execrows
+UPDATE users
+SET totp_enabled_at = NOW(), totp_last_step = $1, updated_at = NOW()
+WHERE id = $2 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL;
+
+-- name: DisableTOTP :exec
+UPDATE users
+SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UseTOTPStep :execrows
+UPDATE users
+SET totp_last_step = $1
+WHERE id = $2 AND totp_last_step < $1;
+
+-- name: CreateRecoveryCodes :exec
+INSERT INTO mfa_recovery_codes (code_hash, user_id, created_at)
+SELECT unnest(sqlc.arg('code_hashes')::text[]), sqlc.arg('user_id'), NOW();
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE code_hash = $1 AND user_id = $2 AND used_at IS NULL;
+
+-- name: CreateMFAChallenge :exec
+INSERT INTO mfa_challenges (token_hash, user_id, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    $3
+);
+
+-- name: AttemptMFAChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE token_hash = $1 AND consumed_at IS NULL AND expires_at > NOW() AND attempts < $2
+RETURNING user_id;
+
+-- name: ConsumeMFAChallenge :execrows
+UPDATE mfa_challenges
+SET consumed_at = NOW()
+WHERE token_hash = $1 AND consumed_at IS NULL;
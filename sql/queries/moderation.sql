@@ -0,0 +1,37 @@
+-- name: ListModerationTerms :many
+SELECT * FROM moderation_terms
+ORDER BY term ASC;
+
+-- name: UpsertModerationTerm :one
+INSERT INTO moderation_terms (term, action, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (term) DO UPDATE SET action = EXCLUDED.action, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteModerationTerm :execrows
+DELETE FROM moderation_terms
+WHERE term = $1;
+
+-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags (id, chirp_id, terms, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+);
+
+-- name: ListUnresolvedModerationFlags :many
+SELECT * FROM moderation_flags
+WHERE resolved_at IS NULL
+ORDER BY created_at ASC;
+
+-- name: ResolveModerationFlag :execrows
+UPDATE moderation_flags
+SET resolved_at = NOW()
+WHERE id = $1 AND resolved_at IS NULL;
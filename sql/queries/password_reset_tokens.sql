@@ -0,0 +1,30 @@
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, user_id, email, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW(),
+    $4
+);
+
+-- name: GetLatestPasswordResetTime :one
+SELECT MAX(created_at)::timestamp FROM password_reset_tokens
+WHERE user_id = $1;
+
+-- name: DeleteUnusedPasswordResetTokens :exec
+DELETE FROM password_reset_tokens
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: ResetPassword :one
+WITH used AS (
+    UPDATE password_reset_tokens
+    SET used_at = NOW()
+    WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+    RETURNING user_id, email
+)
+UPDATE users
+SET hashed_password = $2, updated_at = NOW(), email_verified_at = COALESCE(email_verified_at, NOW())
+FROM used
+WHERE users.id = used.user_id AND users.email = used.email
+RETURNING users.id, users.email;
@@ -1,11 +1,15 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, expires_at, user_id)
+INSERT INTO refresh_tokens (token, created_at, updated_at, expires_at, user_id, family_id, user_agent, ip_address, last_used_at)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
-    $3
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
 )
 RETURNING *;
 
@@ -13,7 +17,45 @@ RETURNING *;
 SELECT * FROM refresh_tokens
 WHERE token = $1;
 
--- name: RevokeToken :exec
+-- name: RotateRefreshToken :one
+WITH rotated AS (
+    UPDATE refresh_tokens
+    SET revoked_at = NOW(), updated_at = NOW(), replaced_by = sqlc.arg('new_token')
+    WHERE token = sqlc.arg('token') AND revoked_at IS NULL AND expires_at > NOW()
+    RETURNING user_id, family_id, user_agent, ip_address
+)
+INSERT INTO refresh_tokens (token, created_at, updated_at, expires_at, user_id, family_id, user_agent, ip_address, last_used_at)
+SELECT sqlc.arg('new_token'), NOW(), NOW(), sqlc.arg('expires_at'), rotated.user_id, rotated.family_id, rotated.user_agent, rotated.ip_address, NOW()
+FROM rotated
+RETURNING *;
+
+-- name: RevokeToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE token = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserRefreshTokens :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: ListSessions :many
+SELECT
+    current.family_id AS id,
+    current.user_agent,
+    current.ip_address,
+    (SELECT MIN(family.created_at) FROM refresh_tokens family WHERE family.family_id = current.family_id)::timestamp AS created_at,
+    current.last_used_at
+FROM refresh_tokens current
+WHERE current.user_id = $1 AND current.revoked_at IS NULL AND current.expires_at > NOW()
+ORDER BY current.last_used_at DESC;
+
+-- name: RevokeSession :execrows
 UPDATE refresh_tokens
-SET revoked_at = $1, updated_at = $2, expires_at = $2
-WHERE token = $3;
\ No newline at end of file
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL;
@@ -0,0 +1,76 @@
+-- name: GetSubscription :one
+SELECT * FROM subscriptions
+WHERE user_id = $1;
+
+-- name: ActivateSubscription :one
+INSERT INTO subscriptions (user_id, plan, status, current_period_start, current_period_end, grace_until, created_at, updated_at)
+VALUES (
+    $1,
+    $2,
+    'active',
+    $3,
+    $4,
+    NULL,
+    NOW(),
+    NOW()
+)
+ON CONFLICT (user_id) DO UPDATE
+SET plan = EXCLUDED.plan,
+    status = 'active',
+    current_period_start = EXCLUDED.current_period_start,
+    current_period_end = EXCLUDED.current_period_end,
+    grace_until = NULL,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: CancelSubscription :one
+UPDATE subscriptions
+SET status = 'canceled', grace_until = NULL, updated_at = NOW()
+WHERE user_id = $1
+RETURNING *;
+
+-- name: MarkSubscriptionPastDue :one
+UPDATE subscriptions
+SET status = 'past_due',
+    grace_until = CASE WHEN status = 'past_due' AND grace_until IS NOT NULL THEN grace_until ELSE $2 END,
+    updated_at = NOW()
+WHERE user_id = $1 AND status IN ('active', 'past_due')
+RETURNING *;
+
+-- name: CreateSubscriptionEvent :exec
+INSERT INTO subscription_events (id, user_id, event, status, delivery_id, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+);
+
+-- name: ExpireLapsedSubscriptions :many
+WITH expired AS (
+    UPDATE subscriptions
+    SET status = 'expired', grace_until = NULL, updated_at = NOW()
+    WHERE status IN ('active', 'past_due')
+    AND (grace_until < NOW() OR (grace_until IS NULL AND current_period_end < sqlc.arg('lapsed_before')))
+    RETURNING user_id
+), history AS (
+    INSERT INTO subscription_events (id, user_id, event, status, created_at)
+    SELECT gen_random_uuid(), user_id, 'expired', 'expired', NOW() FROM expired
+)
+SELECT user_id FROM expired;
+
+-- name: SyncChirpyRed :execrows
+UPDATE users
+SET is_chirpy_red = NOT is_chirpy_red, updated_at = NOW()
+WHERE is_chirpy_red <> EXISTS (
+    SELECT 1 FROM subscriptions
+    WHERE subscriptions.user_id = users.id AND subscriptions.status IN ('active', 'past_due')
+);
+
+-- name: GetUserPlan :one
+SELECT COALESCE((
+    SELECT plan FROM subscriptions
+    WHERE user_id = $1 AND status IN ('active', 'past_due')
+), '')::text AS plan;
@@ -13,16 +13,55 @@ RETURNING id, created_at, updated_at, email, is_chirpy_red;
 SELECT * FROM users
 WHERE email = $1;
 
+-- name: GetUserFromID :one
+SELECT * FROM users
+WHERE id = $1;
+
 -- name: UpdateUser :one
 UPDATE users
-SET email = $1, hashed_password = $2
+SET email = $1, hashed_password = $2, updated_at = NOW(),
+    email_verified_at = CASE WHEN email = $1 THEN email_verified_at END
 WHERE id = $3
 RETURNING id, created_at, updated_at, email, is_chirpy_red;
 
--- name: UpgradeUser :exec
+-- name: SetUserChirpyRed :execrows
 UPDATE users
-SET is_chirpy_red = TRUE
+SET is_chirpy_red = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserTokenVersion :one
+SELECT token_version FROM users
 WHERE id = $1;
 
+-- name: BumpUserTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING token_version;
+
 -- name: DeleteUsers :exec
-DELETE FROM users;
\ No newline at end of file
+DELETE FROM users;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2, token_version = token_version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING token_version;
+
+-- name: ListUsersAfter :many
+SELECT * FROM users
+WHERE (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+AND (sqlc.narg('role')::text IS NULL OR role = sqlc.narg('role'))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListUsersBefore :many
+SELECT * FROM users
+WHERE (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+AND (sqlc.narg('role')::text IS NULL OR role = sqlc.narg('role'))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
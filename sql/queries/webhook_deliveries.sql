@@ -0,0 +1,34 @@
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, source, delivery_id, event, headers, body, received_at, status_code, outcome, error, replay_of)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
+)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: ListWebhookDeliveriesAfter :many
+SELECT * FROM webhook_deliveries
+WHERE (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (received_at, id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+AND (sqlc.narg('outcome')::text IS NULL OR outcome = sqlc.narg('outcome'))
+ORDER BY received_at ASC, id ASC
+LIMIT sqlc.arg('row_limit');
+
+-- name: ListWebhookDeliveriesBefore :many
+SELECT * FROM webhook_deliveries
+WHERE (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (received_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid))
+AND (sqlc.narg('outcome')::text IS NULL OR outcome = sqlc.narg('outcome'))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg('row_limit');
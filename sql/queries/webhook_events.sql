@@ -0,0 +1,8 @@
+-- name: CreateWebhookEvent :execrows
+INSERT INTO webhook_events (delivery_id, event, received_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (delivery_id) DO NOTHING;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- The search document lives beside chirps rather than on it so that the
+-- chirp queries selecting every column don't carry the tsvector around.
+CREATE TABLE chirp_search (
+    chirp_id UUID PRIMARY KEY,
+    document tsvector NOT NULL,
+    FOREIGN KEY(chirp_id) REFERENCES chirps (id) ON DELETE CASCADE
+);
+
+CREATE INDEX chirp_search_document_idx ON chirp_search USING GIN (document);
+
+-- +goose StatementBegin
+CREATE FUNCTION chirp_search_refresh() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO chirp_search (chirp_id, document)
+    VALUES (NEW.id, to_tsvector('english', NEW.body))
+    ON CONFLICT (chirp_id) DO UPDATE SET document = EXCLUDED.document;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER chirp_search_refresh
+AFTER INSERT OR UPDATE OF body ON chirps
+FOR EACH ROW EXECUTE FUNCTION chirp_search_refresh();
+
+INSERT INTO chirp_search (chirp_id, document)
+SELECT id, to_tsvector('english', body) FROM chirps;
+
+-- +goose Down
+DROP TRIGGER chirp_search_refresh ON chirps;
+DROP FUNCTION chirp_search_refresh;
+DROP TABLE chirp_search;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE moderation_terms (
+    term TEXT PRIMARY KEY,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject', 'flag')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE moderation_flags (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL,
+    terms TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    FOREIGN KEY(chirp_id) REFERENCES chirps (id) ON DELETE CASCADE
+);
+
+CREATE INDEX moderation_flags_unresolved_idx ON moderation_flags (created_at) WHERE resolved_at IS NULL;
+
+-- +goose Down
+DROP TABLE moderation_flags;
+DROP TABLE moderation_terms;
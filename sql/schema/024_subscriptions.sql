@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE subscriptions (
+    user_id UUID PRIMARY KEY,
+    plan TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('active', 'past_due', 'canceled', 'expired')),
+    current_period_start TIMESTAMP NOT NULL,
+    current_period_end TIMESTAMP,
+    grace_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY(user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX subscriptions_lapsing_idx ON subscriptions (current_period_end)
+WHERE status IN ('active', 'past_due');
+
+CREATE TABLE subscription_events (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    event TEXT NOT NULL,
+    status TEXT NOT NULL,
+    delivery_id TEXT,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY(user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX subscription_events_user_id_idx ON subscription_events (user_id, created_at);
+
+-- Users upgraded before subscriptions were tracked keep Chirpy Red with no
+-- end date.
+INSERT INTO subscriptions (user_id, plan, status, current_period_start, current_period_end, created_at, updated_at)
+SELECT id, 'red', 'active', updated_at, NULL, NOW(), NOW() FROM users
+WHERE is_chirpy_red;
+
+-- +goose Down
+DROP TABLE subscription_events;
+DROP TABLE subscriptions;
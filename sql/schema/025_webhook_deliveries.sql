@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    source TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    event TEXT NOT NULL,
+    headers JSONB NOT NULL,
+    body BYTEA NOT NULL,
+    received_at TIMESTAMP NOT NULL,
+    status_code INTEGER NOT NULL,
+    outcome TEXT NOT NULL CHECK (outcome IN ('processed', 'duplicate', 'rejected', 'failed')),
+    error TEXT NOT NULL,
+    replay_of UUID,
+    FOREIGN KEY(replay_of) REFERENCES webhook_deliveries (id) ON DELETE SET NULL
+);
+
+CREATE INDEX webhook_deliveries_received_at_idx ON webhook_deliveries (received_at, id);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
@@ -0,0 +1,22 @@
+-- Index the hashtags and mentions of chirps posted before they were
+-- extracted. The patterns mirror hashtagPattern and mentionPattern in
+-- hashtags.go.
+
+-- +goose Up
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT chirps.id, lower(match[1]), chirps.created_at
+FROM chirps,
+    regexp_matches(chirps.body, '(?:^|[^[:alnum:]_&])#([[:alnum:]_]*[[:alpha:]][[:alnum:]_]*)', 'g') AS match
+WHERE chirps.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO chirp_mentions (chirp_id, handle, created_at)
+SELECT chirps.id, lower(match[1]), chirps.created_at
+FROM chirps,
+    regexp_matches(chirps.body, '(?:^|[^[:alnum:]_])@([[:alnum:]_]{1,30})', 'g') AS match
+WHERE chirps.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+-- The backfilled rows can't be told apart from ones recorded since, so they
+-- are left in place.
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/subscription"
+)
+
+const defaultSubscriptionPlan = "red"
+
+var (
+	errUserNotFound         = errors.New("user not found")
+	errSubscriptionNotFound = errors.New("subscription not found")
+)
+
+// applySubscriptionEvent updates the user's subscription for a Polka event,
+// records it in the subscription's history and brings is_chirpy_red in line.
+func (cfg *apiConfig) applySubscriptionEvent(ctx context.Context, q *database.Queries, userID uuid.UUID, event polkaEvent, deliveryID string) error {
+	if _, err := q.GetUserFromID(ctx, userID); errors.Is(err, sql.ErrNoRows) {
+		return errUserNotFound
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var updated database.Subscription
+	var err error
+	switch event.Event {
+	case "user.upgraded", "user.renewed":
+		start := now
+		if event.Event == "user.renewed" {
+			// Renewing early extends the current period rather than
+			// starting a new one today.
+			current, err := q.GetSubscription(ctx, userID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			if err == nil && subscription.IsRed(current.Status) && current.CurrentPeriodEnd.Valid && current.CurrentPeriodEnd.Time.After(now) {
+				start = current.CurrentPeriodEnd.Time
+			}
+		}
+		if event.Data.PeriodStart != nil {
+			start = *event.Data.PeriodStart
+		}
+		end := start.Add(cfg.subscriptionPeriod)
+		if event.Data.PeriodEnd != nil {
+			end = *event.Data.PeriodEnd
+		}
+		plan := event.Data.Plan
+		if len(plan) == 0 {
+			plan = defaultSubscriptionPlan
+		}
+		updated, err = q.ActivateSubscription(ctx, database.ActivateSubscriptionParams{
+			UserID:             userID,
+			Plan:               plan,
+			CurrentPeriodStart: start,
+			CurrentPeriodEnd:   sql.NullTime{Time: end, Valid: true},
+		})
+	case "user.downgraded":
+		updated, err = q.CancelSubscription(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			updated, err = database.Subscription{Status: "canceled"}, nil
+		}
+	case "payment.failed":
+		current, err := q.GetSubscription(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errSubscriptionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		graceUntil := subscription.GraceUntil(current.Status, current.GraceUntil, now, cfg.subscriptionGracePeriod)
+		updated, err = q.MarkSubscriptionPastDue(ctx, database.MarkSubscriptionPastDueParams{
+			UserID:     userID,
+			GraceUntil: sql.NullTime{Time: graceUntil, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errSubscriptionNotFound
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.SetUserChirpyRed(ctx, database.SetUserChirpyRedParams{ID: userID, IsChirpyRed: subscription.IsRed(updated.Status)}); err != nil {
+		return err
+	}
+	return q.CreateSubscriptionEvent(ctx, database.CreateSubscriptionEventParams{
+		UserID:     userID,
+		Event:      event.Event,
+		Status:     updated.Status,
+		DeliveryID: sql.NullString{String: deliveryID, Valid: true},
+	})
+}
+
+// sweepSubscriptions expires subscriptions whose period, plus the grace
+// period, has passed without a renewal, and past-due ones whose grace period
+// is over, then makes every user's is_chirpy_red match their subscription.
+func (cfg *apiConfig) sweepSubscriptions(ctx context.Context) error {
+	expired, err := cfg.db.ExpireLapsedSubscriptions(ctx, time.Now().Add(-cfg.subscriptionGracePeriod))
+	if err != nil {
+		return err
+	}
+	synced, err := cfg.db.SyncChirpyRed(ctx)
+	if err != nil {
+		return err
+	}
+	if len(expired) != 0 || synced != 0 {
+		log.Printf("Expired %d subscriptions and updated Chirpy Red for %d users", len(expired), synced)
+	}
+	return nil
+}
+
+// runSubscriptionSweeper sweeps subscriptions straight away and then every
+// interval until ctx is done.
+func (cfg *apiConfig) runSubscriptionSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := cfg.sweepSubscriptions(ctx); err != nil {
+			log.Printf("Error sweeping subscriptions: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/louiehdev/chirpy/internal/database"
+	"github.com/louiehdev/chirpy/internal/polka"
+)
+
+const polkaWebhookSource = "polka"
+
+var webhookOutcomes = []string{"processed", "duplicate", "rejected", "failed"}
+
+// Deliveries whose signature didn't verify could come from anyone, so only
+// this much of their body is kept.
+const maxUnverifiedWebhookBodySize = 4 << 10
+
+// redactedWebhookHeaders are left out of the delivery log. Polka doesn't
+// send them, but anything that does shouldn't have its credentials stored.
+var redactedWebhookHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// logWebhookDelivery records a delivery's headers, raw body and how it was
+// handled. The delivery ID is taken from the headers even when the signature
+// didn't verify, so that rejected deliveries can be matched up with Polka's.
+// replayOf names the original delivery when this is a replay.
+func (cfg *apiConfig) logWebhookDelivery(ctx context.Context, header http.Header, body []byte, receivedAt time.Time, result webhookResult, replayOf uuid.NullUUID) (database.WebhookDelivery, error) {
+	stored := header.Clone()
+	for _, name := range redactedWebhookHeaders {
+		stored.Del(name)
+	}
+	headers, err := json.Marshal(stored)
+	if err != nil {
+		return database.WebhookDelivery{}, err
+	}
+	if body == nil {
+		body = []byte{}
+	}
+	if !result.verified && len(body) > maxUnverifiedWebhookBodySize {
+		body = body[:maxUnverifiedWebhookBodySize]
+	}
+	var message string
+	if result.err != nil {
+		message = result.err.Error()
+	}
+	return cfg.db.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		Source:     polkaWebhookSource,
+		DeliveryID: header.Get(polka.DeliveryHeader),
+		Event:      result.event,
+		Headers:    headers,
+		Body:       body,
+		ReceivedAt: receivedAt,
+		StatusCode: int32(result.status),
+		Outcome:    result.outcome,
+		Error:      message,
+		ReplayOf:   replayOf,
+	})
+}